@@ -0,0 +1,82 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+)
+
+// BigFunc is the arbitrary-precision counterpart of OpFunc, used when a
+// calculation runs with RunOptions.BigInt.
+type BigFunc func(args []*big.Int) (*big.Int, error)
+
+// maxBigBits bounds the size of big integer results so that neither a single
+// instruction nor a chain of them such as x = x * x can exhaust memory.
+const maxBigBits = 1 << 20
+
+var ErrResultTooLarge = errors.New("result too large")
+
+// bounded returns z unless it has more than maxBigBits bits.
+func bounded(z *big.Int) (*big.Int, error) {
+	if z.BitLen() > maxBigBits {
+		return nil, ErrResultTooLarge
+	}
+	return z, nil
+}
+
+func bigAdd(args []*big.Int) (*big.Int, error) {
+	return bounded(new(big.Int).Add(args[0], args[1]))
+}
+
+func bigSub(args []*big.Int) (*big.Int, error) {
+	return bounded(new(big.Int).Sub(args[0], args[1]))
+}
+
+func bigMul(args []*big.Int) (*big.Int, error) {
+	// The product has at least this many bits, so it is not computed.
+	if x, y := args[0].BitLen(), args[1].BitLen(); x > 0 && y > 0 && x+y-1 > maxBigBits {
+		return nil, ErrResultTooLarge
+	}
+	return bounded(new(big.Int).Mul(args[0], args[1]))
+}
+
+func bigDiv(args []*big.Int) (*big.Int, error) {
+	if args[1].Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return new(big.Int).Quo(args[0], args[1]), nil
+}
+
+func bigMod(args []*big.Int) (*big.Int, error) {
+	if args[1].Sign() == 0 {
+		return nil, ErrDivisionByZero
+	}
+	return new(big.Int).Rem(args[0], args[1]), nil
+}
+
+func bigPow(args []*big.Int) (*big.Int, error) {
+	base, exp := args[0], args[1]
+	if exp.Sign() < 0 {
+		return nil, ErrNegativeExponent
+	}
+	// The power has at least exp*(bits-1)+1 bits, so it is not computed.
+	if bits := base.BitLen(); bits > 1 {
+		if !exp.IsInt64() || exp.Int64() > maxBigBits/int64(bits-1) {
+			return nil, ErrResultTooLarge
+		}
+	}
+	return bounded(new(big.Int).Exp(base, exp, nil))
+}
+
+func bigMin(args []*big.Int) (*big.Int, error) {
+	if args[0].Cmp(args[1]) <= 0 {
+		return new(big.Int).Set(args[0]), nil
+	}
+	return new(big.Int).Set(args[1]), nil
+}
+
+func bigMax(args []*big.Int) (*big.Int, error) {
+	if args[0].Cmp(args[1]) >= 0 {
+		return new(big.Int).Set(args[0]), nil
+	}
+	return new(big.Int).Set(args[1]), nil
+}
@@ -0,0 +1,124 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestBigIntMode(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "*", Var: "a", Left: "123456789012345678901234567890", Right: int64(1000)},
+		{Type: "calc", Op: "**", Var: "b", Left: int64(2), Right: int64(100)},
+		{Type: "calc", Op: "-", Var: "c", Left: "b", Right: "b"},
+		{Type: "calc", Op: "+", Var: "d", Left: "c", Right: "-42"},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "b"},
+		{Type: "print", Var: "d"},
+	}
+
+	report, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Execute(context.Background(), instructions, RunOptions{BigInt: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	expected := []Result{
+		{Var: "a", Exact: "123456789012345678901234567890000"},
+		{Var: "b", Exact: "1267650600228229401496703205376"},
+		{Var: "d", Value: -42, Exact: "-42"},
+	}
+	if len(report.Results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(report.Results))
+	}
+	for i, res := range report.Results {
+		if res != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], res)
+		}
+	}
+}
+
+func TestBigIntModeErrors(t *testing.T) {
+	registry := DefaultRegistry()
+	registry.Register("neg", 1, func(args []int64) (int64, error) { return -args[0], nil }, 0)
+	calc := NewCalculator(WithRegistry(registry), WithClock(NewVirtualClock(at(0))))
+
+	_, err := calc.Execute(context.Background(), []Instruction{
+		{Type: "calc", Op: "neg", Var: "x", Left: int64(1)},
+		{Type: "print", Var: "x"},
+	}, RunOptions{BigInt: true})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Kind != UnsupportedOperation {
+		t.Errorf("expected unsupported operation, got %v", err)
+	}
+
+	_, err = calc.Execute(context.Background(), []Instruction{
+		{Type: "calc", Op: "**", Var: "x", Left: int64(3), Right: "100000000000"},
+		{Type: "print", Var: "x"},
+	}, RunOptions{BigInt: true})
+	if !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("expected ErrResultTooLarge, got %v", err)
+	}
+
+	_, err = calc.Execute(context.Background(), []Instruction{
+		{Type: "calc", Op: "/", Var: "x", Left: "1", Right: "0"},
+		{Type: "print", Var: "x"},
+	}, RunOptions{BigInt: true})
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected ErrDivisionByZero, got %v", err)
+	}
+}
+
+func TestBigIntResultLimit(t *testing.T) {
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
+	_, err := calc.Execute(context.Background(), []Instruction{
+		{Type: "calc", Op: "**", Var: "x", Left: int64(2), Right: int64(600000)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: "x"},
+		{Type: "print", Var: "y"},
+	}, RunOptions{BigInt: true})
+	if !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("x * x: expected ErrResultTooLarge, got %v", err)
+	}
+
+	max := new(big.Int).Lsh(big.NewInt(1), maxBigBits-1)
+	if _, err := bigAdd([]*big.Int{max, big.NewInt(-1)}); err != nil {
+		t.Errorf("+: unexpected error %v", err)
+	}
+	if _, err := bigSub([]*big.Int{max, max}); err != nil {
+		t.Errorf("-: unexpected error %v", err)
+	}
+	if _, err := bigAdd([]*big.Int{max, max}); err != ErrResultTooLarge {
+		t.Errorf("+: expected ErrResultTooLarge, got %v", err)
+	}
+	if _, err := bigSub([]*big.Int{new(big.Int).Neg(max), max}); err != ErrResultTooLarge {
+		t.Errorf("-: expected ErrResultTooLarge, got %v", err)
+	}
+	if _, err := bigMul([]*big.Int{max, big.NewInt(1)}); err != nil {
+		t.Errorf("*: unexpected error %v", err)
+	}
+	if _, err := bigMul([]*big.Int{max, big.NewInt(0)}); err != nil {
+		t.Errorf("*: unexpected error %v", err)
+	}
+	if _, err := bigMul([]*big.Int{max, big.NewInt(2)}); err != ErrResultTooLarge {
+		t.Errorf("*: expected ErrResultTooLarge, got %v", err)
+	}
+	if _, err := bigPow([]*big.Int{big.NewInt(2), big.NewInt(maxBigBits - 1)}); err != nil {
+		t.Errorf("**: unexpected error %v", err)
+	}
+	if _, err := bigPow([]*big.Int{big.NewInt(2), big.NewInt(maxBigBits)}); err != ErrResultTooLarge {
+		t.Errorf("**: expected ErrResultTooLarge, got %v", err)
+	}
+}
+
+func TestIntModeStringLiterals(t *testing.T) {
+	results, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: "40", Right: "+2"},
+		{Type: "print", Var: "x"},
+	})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != 42 || results[0].Exact != "" {
+		t.Errorf("expected x = 42 without exact value, got %v", results)
+	}
+}
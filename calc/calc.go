@@ -1,141 +1,317 @@
 package calc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
 	"sync"
 	"time"
 )
 
-func NewCalculator() *Calculator {
-	return &Calculator{
-		vars:  sync.Map{},
-		ready: make(map[string]*sync.WaitGroup),
+type Option func(*Calculator)
+
+// WithRegistry makes the Calculator use the operations of r instead of the
+// built-in ones.
+func WithRegistry(r *OperationRegistry) Option {
+	return func(c *Calculator) {
+		c.registry = r
+	}
+}
+
+type OverflowPolicy int
+
+const (
+	// OverflowWrap keeps the two's complement result, like plain int64
+	// arithmetic.
+	OverflowWrap OverflowPolicy = iota
+	// OverflowError fails the instruction with ErrOverflow.
+	OverflowError
+	// OverflowSaturate clamps the result to math.MaxInt64 or math.MinInt64.
+	OverflowSaturate
+)
+
+func WithOverflowPolicy(p OverflowPolicy) Option {
+	return func(c *Calculator) {
+		c.overflow = p
 	}
 }
 
-var operations = map[string]func(int64, int64) int64{
-	"+": func(a, b int64) int64 { time.Sleep(50 * time.Millisecond); return a + b },
-	"-": func(a, b int64) int64 { time.Sleep(50 * time.Millisecond); return a - b },
-	"*": func(a, b int64) int64 { time.Sleep(50 * time.Millisecond); return a * b },
+// DefaultWorkers is the number of instructions a single calculation computes
+// at once unless WithWorkers is given: a few per CPU, since operations mostly
+// wait for their latency.
+func DefaultWorkers() int {
+	return 4 * runtime.GOMAXPROCS(0)
 }
 
-func (c *Calculator) Calculate(instructions []Instruction) ([]Result, error) {
-	var calcOps []Instruction
-	var printOps []Instruction
-
-	for _, instr := range instructions {
-		switch instr.Type {
-		case "print":
-			printOps = append(printOps, instr)
-		case "calc":
-			calcOps = append(calcOps, instr)
-		default:
-			return nil, fmt.Errorf("unknown operation: '%s'", instr.Type)
-		}
+// WithWorkers limits the number of instructions a single calculation computes
+// at once, DefaultWorkers if not given. Zero puts no limit.
+func WithWorkers(n int) Option {
+	return func(c *Calculator) {
+		c.workers = max(n, 0)
 	}
+}
 
-	for _, instr := range calcOps {
-		if _, exists := c.ready[instr.Var]; !exists {
-			c.ready[instr.Var] = &sync.WaitGroup{}
-			c.ready[instr.Var].Add(1)
-		}
+func NewCalculator(opts ...Option) *Calculator {
+	c := &Calculator{
+		registry: DefaultRegistry(),
+		decimal:  DefaultDecimalConfig,
+		workers:  DefaultWorkers(),
+		clock:    realClock{},
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
+}
 
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(calcOps))
+func (c *Calculator) Operations() []Operation {
+	return c.registry.Operations()
+}
 
-	for _, instr := range calcOps {
-		wg.Add(1)
-		go func(instr Instruction) {
-			defer wg.Done()
-			for _, dep := range getDependencies(instr) {
-				c.ready[dep].Wait()
-			}
-			if err := c.processCalc(instr); err != nil {
-				errChan <- err
-				return
-			}
-			c.ready[instr.Var].Done()
-		}(instr)
-	}
+// IncompleteError is returned when the context of a calculation is done
+// before every calc instruction has been computed.
+type IncompleteError struct {
+	Err     error
+	Pending []string
+}
 
-	go func() {
-		wg.Wait()
-		close(errChan)
-	}()
+func (e *IncompleteError) Error() string {
+	return fmt.Sprintf("calculation interrupted: %v (uncomputed: %s)", e.Err, strings.Join(e.Pending, ", "))
+}
 
-	for err := range errChan {
+func (e *IncompleteError) Unwrap() error {
+	return e.Err
+}
+
+func (c *Calculator) Calculate(instructions []Instruction) ([]Result, error) {
+	return c.CalculateContext(context.Background(), instructions)
+}
+
+// CalculateContext is like Calculate but stops scheduling operations and
+// interrupts the running ones once ctx is done.
+func (c *Calculator) CalculateContext(ctx context.Context, instructions []Instruction) ([]Result, error) {
+	report, err := c.Execute(ctx, instructions, RunOptions{})
+	if err != nil {
 		return nil, err
 	}
+	return report.Results, nil
+}
 
-	var results []Result
-	for _, printInstr := range printOps {
-		if val, ok := c.vars.Load(printInstr.Var); ok {
-			results = append(results, Result{Var: printInstr.Var, Value: val.(int64)})
-		}
+// execution holds the state of a single calculation, so one Calculator can
+// serve any number of concurrent requests.
+type execution struct {
+	calc   *Calculator
+	vars   sync.Map
+	nodes  map[string]*node
+	params map[string]interface{}
+	bigInt bool
+}
+
+func newExecution(c *Calculator) *execution {
+	return &execution{
+		calc:  c,
+		nodes: make(map[string]*node),
 	}
+}
 
-	c.Reset()
+// node tracks the execution of one calc instruction. pending counts the
+// dependencies that have not finished yet; the node becomes ready once it
+// drops to zero.
+type node struct {
+	index    int
+	instr    Instruction
+	priority time.Duration
+	pending  int
+	status   Status
+	err      error
 
-	return results, nil
+	worker            int
+	ready, start, end time.Time
+}
+
+// Execute runs the batch like Calculate and additionally reports the status
+// of every calc instruction. When an instruction fails, its dependents are
+// skipped, all goroutines are joined and the first failure in instruction
+// order is returned together with the report. If ctx is done first, the
+// instructions left over are marked canceled and an *IncompleteError is
+// returned.
+//
+// Unless opts.FullEvaluation is set, only the calc instructions that some
+// print depends on are executed; the rest are reported as pruned.
+func (c *Calculator) Execute(ctx context.Context, instructions []Instruction, opts RunOptions) (*Report, error) {
+	return c.ExecuteStream(ctx, instructions, opts, nil)
+}
+
+// ExecuteStream is like Execute and passes the results of the prints to emit
+// as soon as they are computed, see Program.RunStream.
+func (c *Calculator) ExecuteStream(ctx context.Context, instructions []Instruction, opts RunOptions, emit func(Result) error) (*Report, error) {
+	p, err := c.PrepareOptions(instructions, opts)
+	if err != nil {
+		return nil, err
+	}
+	return p.RunStream(ctx, nil, emit)
 }
 
 func getDependencies(instr Instruction) []string {
 	var deps []string
-	if v, ok := instr.Left.(string); ok {
-		deps = append(deps, v)
+	if isVariable(instr.Left) {
+		deps = append(deps, instr.Left.(string))
 	}
-	if v, ok := instr.Right.(string); ok {
-		deps = append(deps, v)
+	if isVariable(instr.Right) {
+		deps = append(deps, instr.Right.(string))
 	}
 	return deps
 }
 
-func (c *Calculator) processCalc(instr Instruction) error {
-	if _, exists := c.vars.Load(instr.Var); exists {
-		return fmt.Errorf("variable %s already exists", instr.Var)
+// isVariable reports whether an operand refers to a variable. Operand
+// strings that are number literals or parameters are not variable names.
+func isVariable(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && !IsLiteral(s) && !isParam(s)
+}
+
+// isParam reports whether an operand is a program parameter such as "$price".
+func isParam(v interface{}) bool {
+	s, ok := v.(string)
+	return ok && len(s) > 1 && s[0] == '$'
+}
+
+// IsLiteral reports whether an operand string is a number literal, either an
+// integer like "-42" or a decimal like "12.345", rather than a variable name.
+func IsLiteral(s string) bool {
+	return isIntLiteral(s) || isDecimalLiteral(s)
+}
+
+func isIntLiteral(s string) bool {
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		s = s[1:]
+	}
+	if s == "" {
+		return false
 	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
 
-	left, err := c.getValue(instr.Left)
-	if err != nil {
-		return err
+func newResult(name string, val interface{}) Result {
+	switch v := val.(type) {
+	case *big.Int:
+		res := Result{Var: name, Exact: v.String()}
+		if v.IsInt64() {
+			res.Value = v.Int64()
+		}
+		return res
+	case Decimal:
+		res := Result{Var: name, Exact: v.String()}
+		if n, ok := v.Int(); ok && n.IsInt64() {
+			res.Value = n.Int64()
+		}
+		return res
+	default:
+		return Result{Var: name, Value: v.(int64)}
 	}
+}
 
-	right, err := c.getValue(instr.Right)
+func (e *execution) processCalc(ctx context.Context, index int, instr Instruction) error {
+	if _, exists := e.vars.Load(instr.Var); exists {
+		return &ValidationError{Kind: DuplicateVariable, Index: index, Var: instr.Var}
+	}
+	val, err := e.compute(ctx, index, instr)
 	if err != nil {
 		return err
 	}
+	e.vars.Store(instr.Var, val)
+	return nil
+}
 
-	op, ok := operations[instr.Op]
+// compute evaluates a calc instruction, taking the latency of its
+// operation, without storing the value.
+func (e *execution) compute(ctx context.Context, index int, instr Instruction) (interface{}, error) {
+	op, ok := e.calc.registry.Lookup(instr.Op)
 	if !ok {
-		return fmt.Errorf("unknown operation %s", instr.Op)
+		return nil, &ValidationError{Kind: UnknownOperation, Index: index, Var: instr.Var, Op: instr.Op}
 	}
 
-	c.vars.Store(instr.Var, op(left, right))
-	return nil
+	args := make([]interface{}, 0, op.Arity)
+	for _, operand := range []interface{}{instr.Left, instr.Right}[:op.Arity] {
+		val, err := e.operand(operand)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, val)
+	}
+
+	if err := e.calc.clock.Sleep(ctx, op.Latency.Latency(args)); err != nil {
+		return nil, err
+	}
+
+	val, err := e.apply(op, args)
+	if err != nil {
+		return nil, &OperationError{Index: index, Var: instr.Var, Op: instr.Op, Args: args, Err: err}
+	}
+	return val, nil
 }
 
-func (c *Calculator) getValue(v interface{}) (int64, error) {
-	switch val := v.(type) {
-	case int64:
-		return val, nil
-	case float64:
-		return int64(val), nil
-	case string:
-		if stored, ok := c.vars.Load(val); ok {
-			return stored.(int64), nil
+func (e *execution) operand(v interface{}) (interface{}, error) {
+	if isVariable(v) {
+		if stored, ok := e.vars.Load(v); ok {
+			return stored, nil
 		}
-		return 0, fmt.Errorf("variable %s not defined", val)
-	default:
-		return 0, fmt.Errorf("invalid value type")
+		return nil, &UndefinedError{Var: v.(string)}
+	}
+	if isParam(v) {
+		if bound, ok := e.params[v.(string)[1:]]; ok {
+			return bound, nil
+		}
+		return nil, fmt.Errorf("parameter %s not bound", v)
+	}
+	if s, ok := v.(string); ok && isDecimalLiteral(s) {
+		return e.calc.decimal.Parse(s)
 	}
+	return literalValue(v, e.bigInt)
 }
 
-func (c *Calculator) Reset() {
-	c.vars.Range(func(key, value interface{}) bool {
-		c.vars.Delete(key)
-		return true
-	})
+// apply runs op on the operand values. If any operand is a decimal, all of
+// them are promoted to decimals; otherwise they are all int64 or, in big
+// integer mode, all *big.Int.
+func (e *execution) apply(op Operation, args []interface{}) (interface{}, error) {
+	for _, a := range args {
+		if _, ok := a.(Decimal); ok {
+			if op.Decimal == nil {
+				return nil, fmt.Errorf("operation %s has no decimal implementation", op.Name)
+			}
+			cfg := e.calc.decimal
+			decArgs := make([]Decimal, len(args))
+			for i, a := range args {
+				decArgs[i] = cfg.toDecimal(a)
+			}
+			return call(func(args []Decimal) (Decimal, error) { return op.Decimal(args, cfg) }, decArgs)
+		}
+	}
 
-	c.ready = make(map[string]*sync.WaitGroup)
+	if e.bigInt {
+		bigArgs := make([]*big.Int, len(args))
+		for i, a := range args {
+			bigArgs[i] = a.(*big.Int)
+		}
+		return call(op.Big, bigArgs)
+	}
+
+	intArgs := make([]int64, len(args))
+	for i, a := range args {
+		intArgs[i] = a.(int64)
+	}
+	val, err := call(op.Func, intArgs)
+	var o *overflow
+	if errors.As(err, &o) {
+		val, err = o.resolve(e.calc.overflow)
+	}
+	return val, err
 }
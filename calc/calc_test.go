@@ -1,14 +1,24 @@
 package calc
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"runtime"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestProcessCalc(t *testing.T) {
-	calc := NewCalculator()
-	err := calc.processCalc(Instruction{
+	// The test stands in for the scheduler, which keeps the clock informed
+	// of the work in flight.
+	clock := NewVirtualClock(at(0))
+	clock.Begin()
+	defer clock.End()
+	calc := newExecution(NewCalculator(WithClock(clock)))
+	err := calc.processCalc(context.Background(), 0, Instruction{
 		Type: "calc", Op: "+", Var: "a", Left: int64(2), Right: int64(3),
 	})
 	if err != nil {
@@ -19,7 +29,7 @@ func TestProcessCalc(t *testing.T) {
 		t.Errorf("expected 5, got %v", val)
 	}
 
-	err = calc.processCalc(Instruction{
+	err = calc.processCalc(context.Background(), 0, Instruction{
 		Type: "calc", Op: "*", Var: "a", Left: int64(1), Right: int64(1),
 	})
 	if err == nil {
@@ -27,28 +37,34 @@ func TestProcessCalc(t *testing.T) {
 	}
 }
 
-func TestGetValue(t *testing.T) {
-	calc := NewCalculator()
+func TestOperand(t *testing.T) {
+	calc := newExecution(NewCalculator(WithClock(NewVirtualClock(at(0)))))
 	calc.vars.Store("x", int64(42))
 
-	val, err := calc.getValue("x")
-	if err != nil || val != 42 {
-		t.Errorf("expected 42, got %d, err: %v", val, err)
+	val, err := calc.operand("x")
+	if err != nil || val != int64(42) {
+		t.Errorf("expected 42, got %v, err: %v", val, err)
 	}
 
-	val, err = calc.getValue(int64(10))
-	if err != nil || val != 10 {
-		t.Errorf("expected 10, got %d, err: %v", val, err)
+	val, err = calc.operand(int64(10))
+	if err != nil || val != int64(10) {
+		t.Errorf("expected 10, got %v, err: %v", val, err)
 	}
 
-	val, err = calc.getValue(float64(3.0))
-	if err != nil || val != 3 {
-		t.Errorf("expected 3, got %d, err: %v", val, err)
+	val, err = calc.operand(float64(3.0))
+	if err != nil || val != int64(3) {
+		t.Errorf("expected 3, got %v, err: %v", val, err)
 	}
 
-	_, err = calc.getValue("undefined")
-	if err == nil {
-		t.Error("expected error for undefined variable")
+	val, err = calc.operand("1.5")
+	if d, ok := val.(Decimal); err != nil || !ok || d.String() != "1.500000" {
+		t.Errorf("expected decimal 1.500000, got %v, err: %v", val, err)
+	}
+
+	_, err = calc.operand("undefined")
+	var undefined *UndefinedError
+	if !errors.As(err, &undefined) || undefined.Var != "undefined" {
+		t.Errorf("expected undefined variable error, got %v", err)
 	}
 }
 
@@ -61,7 +77,7 @@ func TestSimpleScenario(t *testing.T) {
 		{Type: "print", Var: "x"},
 	}
 
-	calc := NewCalculator()
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
 	results, err := calc.Calculate(instructions)
 
 	if err != nil {
@@ -108,13 +124,18 @@ func TestComplexScenario(t *testing.T) {
 		t.Fatalf("Failed to parse JSON: %v", err)
 	}
 
-	calc := NewCalculator()
+	clock := NewVirtualClock(time.Time{})
+	calc := NewCalculator(WithClock(clock))
 	results, err := calc.Calculate(instructions)
 
 	if err != nil {
 		t.Fatalf("Calculate failed: %v", err)
 	}
 
+	if elapsed := clock.Now().Sub(time.Time{}); elapsed != 3*defaultCost {
+		t.Errorf("expected makespan of 3 operations, got %v", elapsed)
+	}
+
 	expectedResults := map[string]int64{
 		"x": 12,
 		"q": 40,
@@ -139,7 +160,7 @@ func TestComplexScenario(t *testing.T) {
 }
 
 func TestConcurrency(t *testing.T) {
-	calc := NewCalculator()
+	calc := NewCalculator(WithClock(NewVirtualClock(time.Time{})))
 	n := 10000
 	instructions := make([]Instruction, n)
 
@@ -176,3 +197,151 @@ func TestConcurrency(t *testing.T) {
 		}
 	}
 }
+
+func TestFailurePropagation(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "fail", Var: "x", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "+", Var: "y", Left: "x", Right: int64(1)},
+		{Type: "calc", Op: "*", Var: "z", Left: "y", Right: "w"},
+		{Type: "calc", Op: "+", Var: "w", Left: int64(1), Right: int64(1)},
+		{Type: "print", Var: "z"},
+	}
+
+	registry := DefaultRegistry()
+	registry.Register("fail", 2, func([]int64) (int64, error) { return 0, errors.New("boom") }, 0)
+
+	before := runtime.NumGoroutine()
+	report, err := NewCalculator(WithRegistry(registry), WithClock(NewVirtualClock(at(0)))).Execute(context.Background(), instructions, RunOptions{FullEvaluation: true})
+	if err == nil {
+		t.Fatal("expected error from failing operation")
+	}
+	if report == nil {
+		t.Fatal("expected report alongside the error")
+	}
+
+	expected := []Status{StatusFailed, StatusSkipped, StatusSkipped, StatusOK}
+	if len(report.Statuses) != len(expected) {
+		t.Fatalf("expected %d statuses, got %d", len(expected), len(report.Statuses))
+	}
+	for i, st := range report.Statuses {
+		if st.Status != expected[i] {
+			t.Errorf("for %s expected status %s, got %s", st.Var, expected[i], st.Status)
+		}
+		if st.Index != i {
+			t.Errorf("for %s expected index %d, got %d", st.Var, i, st.Index)
+		}
+	}
+
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines leaked: %d before, %d after", before, after)
+	}
+}
+
+func TestCalculateContextCanceled(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(3)},
+		{Type: "calc", Op: "-", Var: "z", Left: "y", Right: int64(4)},
+		{Type: "print", Var: "z"},
+	}
+
+	clock := NewVirtualClock(at(0))
+	ctx, stop := virtualTimeout(clock, 75*time.Millisecond)
+	_, err := NewCalculator(WithClock(clock)).CalculateContext(ctx, instructions)
+	stop()
+	if d := clock.Now().Sub(at(0)); d != 75*time.Millisecond {
+		t.Errorf("expected the calculation to be interrupted at the deadline, ended at %v", d)
+	}
+
+	var incomplete *IncompleteError
+	if !errors.As(err, &incomplete) {
+		t.Fatalf("expected IncompleteError, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected error to wrap context.DeadlineExceeded, got %v", err)
+	}
+	if len(incomplete.Pending) != 2 || incomplete.Pending[0] != "y" || incomplete.Pending[1] != "z" {
+		t.Errorf("expected pending [y z], got %v", incomplete.Pending)
+	}
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(10), Right: int64(2)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(5)},
+		{Type: "print", Var: "y"},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := calc.Calculate(instructions)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if len(results) != 1 || results[0].Value != 60 {
+				errs <- fmt.Errorf("unexpected results %v", results)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPruning(t *testing.T) {
+	rawJSON := `[
+        { "type": "calc", "op": "+", "var": "x", "left": 10, "right": 2 },
+        { "type": "calc", "op": "*", "var": "y", "left": "x", "right": 5 },
+        { "type": "calc", "op": "-", "var": "q", "left": "y", "right": 20 },
+        { "type": "calc", "op": "+", "var": "unusedA", "left": "y", "right": 100 },
+        { "type": "calc", "op": "*", "var": "unusedB", "left": "unusedA", "right": 2 },
+        { "type": "print", "var": "q" },
+        { "type": "calc", "op": "-", "var": "z", "left": "x", "right": 15 },
+        { "type": "print", "var": "z" },
+        { "type": "calc", "op": "+", "var": "ignoreC", "left": "z", "right": "y" },
+        { "type": "print", "var": "x" }
+    ]`
+
+	var instructions []Instruction
+	if err := json.Unmarshal([]byte(rawJSON), &instructions); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
+
+	report, err := calc.Execute(context.Background(), instructions, RunOptions{})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if report.Pruned != 3 {
+		t.Errorf("expected 3 pruned instructions, got %d", report.Pruned)
+	}
+	for _, st := range report.Statuses {
+		pruned := st.Var == "unusedA" || st.Var == "unusedB" || st.Var == "ignoreC"
+		if pruned != (st.Status == StatusPruned) {
+			t.Errorf("unexpected status %s for %s", st.Status, st.Var)
+		}
+	}
+
+	report, err = calc.Execute(context.Background(), instructions, RunOptions{FullEvaluation: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if report.Pruned != 0 {
+		t.Errorf("expected no pruned instructions with full evaluation, got %d", report.Pruned)
+	}
+	for _, st := range report.Statuses {
+		if st.Status != StatusOK {
+			t.Errorf("unexpected status %s for %s", st.Status, st.Var)
+		}
+	}
+}
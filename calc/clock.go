@@ -0,0 +1,184 @@
+package calc
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock is the time source of a Calculator. Operations wait for their
+// latency with Sleep, which must return ctx.Err() if ctx is done first.
+type Clock interface {
+	Now() time.Time
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// WithClock makes the Calculator use c instead of the real time, e.g. a
+// VirtualClock in tests.
+func WithClock(c Clock) Option {
+	return func(calc *Calculator) {
+		calc.clock = c
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// workTracker is implemented by clocks that need to know how many
+// operations are in flight. The scheduler calls Begin when it dispatches an
+// operation and End once it has handled the result.
+type workTracker interface {
+	Begin()
+	End()
+}
+
+type noTracker struct{}
+
+func (noTracker) Begin() {}
+func (noTracker) End()   {}
+
+func trackerOf(c Clock) workTracker {
+	if t, ok := c.(workTracker); ok {
+		return t
+	}
+	return noTracker{}
+}
+
+// VirtualClock is a Clock whose time only moves when every operation in
+// flight is sleeping: it then jumps to the earliest wake-up time. Sleeps
+// return immediately in real time, so a calculation takes exactly the
+// virtual time of its schedule and tests need not wait for latencies.
+type VirtualClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	active   int
+	sleepers sleeperQueue
+	seq      int
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	c.mu.Lock()
+	c.seq++
+	s := &sleeper{at: c.now.Add(d), seq: c.seq, wake: make(chan struct{})}
+	heap.Push(&c.sleepers, s)
+	c.advance()
+	c.mu.Unlock()
+
+	select {
+	case <-s.wake:
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if s.index >= 0 {
+			heap.Remove(&c.sleepers, s.index)
+		}
+		return ctx.Err()
+	}
+}
+
+// Advance moves the time forward by d, waking the sleepers due until then.
+// It is only needed for sleeps outside of a calculation.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	for c.sleepers.Len() > 0 && !c.sleepers[0].at.After(c.now) {
+		close(heap.Pop(&c.sleepers).(*sleeper).wake)
+	}
+}
+
+func (c *VirtualClock) Begin() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active++
+}
+
+func (c *VirtualClock) End() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active--
+	c.advance()
+}
+
+// advance wakes the earliest sleepers if nothing else is in flight.
+func (c *VirtualClock) advance() {
+	if c.active == 0 || c.sleepers.Len() < c.active {
+		return
+	}
+	next := c.sleepers[0].at
+	if next.After(c.now) {
+		c.now = next
+	}
+	for c.sleepers.Len() > 0 && !c.sleepers[0].at.After(c.now) {
+		close(heap.Pop(&c.sleepers).(*sleeper).wake)
+	}
+}
+
+type sleeper struct {
+	at    time.Time
+	seq   int
+	index int
+	wake  chan struct{}
+}
+
+// sleeperQueue orders sleepers by wake-up time, then by arrival.
+type sleeperQueue []*sleeper
+
+func (q sleeperQueue) Len() int { return len(q) }
+
+func (q sleeperQueue) Less(i, j int) bool {
+	if !q[i].at.Equal(q[j].at) {
+		return q[i].at.Before(q[j].at)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q sleeperQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *sleeperQueue) Push(x interface{}) {
+	s := x.(*sleeper)
+	s.index = len(*q)
+	*q = append(*q, s)
+}
+
+func (q *sleeperQueue) Pop() interface{} {
+	old := *q
+	s := old[len(old)-1]
+	s.index = -1
+	*q = old[:len(old)-1]
+	return s
+}
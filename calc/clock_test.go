@@ -0,0 +1,120 @@
+package calc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestVirtualClockSleep(t *testing.T) {
+	clock := NewVirtualClock(time.Time{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := clock.Sleep(ctx, time.Second); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	done := make(chan error)
+	go func() { done <- clock.Sleep(context.Background(), time.Second) }()
+	for {
+		clock.mu.Lock()
+		queued := clock.sleepers.Len() == 1
+		clock.mu.Unlock()
+		if queued {
+			break
+		}
+		runtime.Gosched()
+	}
+	clock.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestVirtualMakespan runs random batches on a virtual clock and checks
+// that they take exactly as long as their schedule: the critical path
+// without a worker limit and the sum of all latencies with a single worker.
+func TestVirtualMakespan(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("a", 2, add, 10*time.Millisecond)
+	r.Register("b", 2, sub, 30*time.Millisecond)
+	r.Register("c", 2, mul, 70*time.Millisecond)
+	costs := map[string]time.Duration{"a": 10 * time.Millisecond, "b": 30 * time.Millisecond, "c": 70 * time.Millisecond}
+
+	rng := rand.New(rand.NewSource(1))
+	for scenario := 0; scenario < 500; scenario++ {
+		n := 1 + rng.Intn(30)
+		var instructions []Instruction
+		path := make(map[string]time.Duration)
+		var longest, total time.Duration
+		for i := 0; i < n; i++ {
+			v := fmt.Sprintf("v%d", i)
+			op := []string{"a", "b", "c"}[rng.Intn(3)]
+			instr := Instruction{Type: "calc", Op: op, Var: v, Left: int64(i), Right: int64(1)}
+			var start time.Duration
+			if i > 0 && rng.Intn(3) > 0 {
+				dep := fmt.Sprintf("v%d", rng.Intn(i))
+				instr.Left = dep
+				start = path[dep]
+			}
+			path[v] = start + costs[op]
+			longest = max(longest, path[v])
+			total += costs[op]
+			instructions = append(instructions, instr)
+		}
+
+		for workers, expected := range map[int]time.Duration{0: longest, 1: total} {
+			clock := NewVirtualClock(time.Time{})
+			calc := NewCalculator(WithRegistry(r), WithClock(clock), WithWorkers(workers))
+			p, err := calc.PrepareOptions(instructions, RunOptions{FullEvaluation: true})
+			if err != nil {
+				t.Fatalf("Prepare failed: %v", err)
+			}
+			if _, err := p.Run(context.Background(), nil); err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			if elapsed := clock.Now().Sub(time.Time{}); elapsed != expected || p.Estimate() != expected {
+				t.Fatalf("scenario %d, workers %d: expected %v, took %v, estimated %v", scenario, workers, expected, elapsed, p.Estimate())
+			}
+		}
+	}
+}
+
+// virtualDeadline is a context that reports context.DeadlineExceeded once it
+// is done, like one made by context.WithTimeout.
+type virtualDeadline struct {
+	context.Context
+}
+
+func (c virtualDeadline) Err() error {
+	if c.Context.Err() != nil {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// virtualTimeout returns a context that expires when clock has advanced by
+// d. The clock then stays at the deadline until stop is called, so work
+// interrupted by the deadline cannot finish before it notices.
+func virtualTimeout(clock *VirtualClock, d time.Duration) (ctx context.Context, stop func()) {
+	ctx, expire := context.WithCancel(context.Background())
+	timer, cancelTimer := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	clock.Begin()
+	go func() {
+		defer close(done)
+		if clock.Sleep(timer, d) == nil {
+			expire()
+		}
+	}()
+	return virtualDeadline{ctx}, func() {
+		cancelTimer()
+		<-done
+		expire()
+		clock.End()
+	}
+}
@@ -0,0 +1,297 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds to the nearest value, ties to the even neighbour.
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds to the nearest value, ties away from zero.
+	RoundHalfUp
+	// RoundDown truncates toward zero.
+	RoundDown
+	// RoundFloor rounds toward negative infinity.
+	RoundFloor
+	// RoundCeiling rounds toward positive infinity.
+	RoundCeiling
+)
+
+var roundingNames = map[RoundingMode]string{
+	RoundHalfEven: "half-even",
+	RoundHalfUp:   "half-up",
+	RoundDown:     "down",
+	RoundFloor:    "floor",
+	RoundCeiling:  "ceiling",
+}
+
+func (m RoundingMode) String() string {
+	if name, ok := roundingNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	for mode, name := range roundingNames {
+		if name == s {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q", s)
+}
+
+// DecimalConfig is the fixed-point format of decimal values: every decimal
+// carries exactly Scale fractional digits, and results of * and / that need
+// more are rounded with Rounding.
+type DecimalConfig struct {
+	Scale    int
+	Rounding RoundingMode
+}
+
+var DefaultDecimalConfig = DecimalConfig{Scale: 6, Rounding: RoundHalfEven}
+
+// WithDecimal sets the scale and rounding mode of decimal values.
+func WithDecimal(scale int, rounding RoundingMode) Option {
+	return func(c *Calculator) {
+		c.decimal = DecimalConfig{Scale: max(scale, 0), Rounding: rounding}
+	}
+}
+
+// Decimal is a fixed-point number: unscaled / 10^scale.
+type Decimal struct {
+	unscaled *big.Int
+	scale    int
+}
+
+// DecimalFunc is the decimal counterpart of OpFunc. Operands are already
+// converted to cfg.Scale and the result must be returned in it too.
+type DecimalFunc func(args []Decimal, cfg DecimalConfig) (Decimal, error)
+
+func (d Decimal) Scale() int {
+	return d.scale
+}
+
+func (d Decimal) Unscaled() *big.Int {
+	return new(big.Int).Set(d.unscaled)
+}
+
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.unscaled).String()
+	sign := ""
+	if d.unscaled.Sign() < 0 {
+		sign = "-"
+	}
+	if d.scale == 0 {
+		return sign + digits
+	}
+	if len(digits) <= d.scale {
+		digits = strings.Repeat("0", d.scale-len(digits)+1) + digits
+	}
+	point := len(digits) - d.scale
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// Int returns the value as an integer if it has no fractional part.
+func (d Decimal) Int() (*big.Int, bool) {
+	q, r := new(big.Int).QuoRem(d.unscaled, pow10(d.scale), new(big.Int))
+	return q, r.Sign() == 0
+}
+
+func (d Decimal) Cmp(o Decimal) int {
+	return d.unscaled.Cmp(o.unscaled)
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Add(d.unscaled, o.unscaled), scale: d.scale}
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{unscaled: new(big.Int).Sub(d.unscaled, o.unscaled), scale: d.scale}
+}
+
+// Mul returns the rounded product, or ErrResultTooLarge if its unscaled value
+// has more than maxBigBits bits.
+func (cfg DecimalConfig) Mul(a, b Decimal) (Decimal, error) {
+	// The rounded product has at least this many bits, so it is not computed.
+	x, y := a.unscaled.BitLen(), b.unscaled.BitLen()
+	if x > 0 && y > 0 && x+y-1-pow10(cfg.Scale).BitLen() > maxBigBits {
+		return Decimal{}, ErrResultTooLarge
+	}
+	p := new(big.Int).Mul(a.unscaled, b.unscaled)
+	return boundedDecimal(Decimal{unscaled: roundQuo(p, pow10(cfg.Scale), cfg.Rounding), scale: cfg.Scale})
+}
+
+func (cfg DecimalConfig) Quo(a, b Decimal) (Decimal, error) {
+	if b.unscaled.Sign() == 0 {
+		return Decimal{}, ErrDivisionByZero
+	}
+	n := new(big.Int).Mul(a.unscaled, pow10(cfg.Scale))
+	return boundedDecimal(Decimal{unscaled: roundQuo(n, b.unscaled, cfg.Rounding), scale: cfg.Scale})
+}
+
+// boundedDecimal applies the big integer result limit to the unscaled value
+// of d, so that chains such as x = x * x cannot exhaust memory.
+func boundedDecimal(d Decimal) (Decimal, error) {
+	if _, err := bounded(d.unscaled); err != nil {
+		return Decimal{}, err
+	}
+	return d, nil
+}
+
+// FromInt converts an integer to a decimal exactly.
+func (cfg DecimalConfig) FromInt(n *big.Int) Decimal {
+	return Decimal{unscaled: new(big.Int).Mul(n, pow10(cfg.Scale)), scale: cfg.Scale}
+}
+
+// Parse reads a decimal literal such as "12.345" or "-0.5". Literals with
+// more fractional digits than cfg.Scale are rejected rather than rounded.
+func (cfg DecimalConfig) Parse(s string) (Decimal, error) {
+	if !isDecimalLiteral(s) && !isIntLiteral(s) {
+		return Decimal{}, fmt.Errorf("%s is not a decimal", s)
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if len(frac) > cfg.Scale {
+		return Decimal{}, fmt.Errorf("%s has more than %d fractional digits", s, cfg.Scale)
+	}
+	n, _ := new(big.Int).SetString(intPart+frac+strings.Repeat("0", cfg.Scale-len(frac)), 10)
+	return Decimal{unscaled: n, scale: cfg.Scale}, nil
+}
+
+// isDecimalLiteral reports whether s is a number with a fractional part,
+// e.g. "12.345". Integers without a point are integer literals.
+func isDecimalLiteral(s string) bool {
+	intPart, frac, ok := strings.Cut(s, ".")
+	return ok && isIntLiteral(intPart) && frac != "" && isIntLiteral(frac) && frac[0] != '-' && frac[0] != '+'
+}
+
+// roundQuo divides n by d and rounds the quotient according to mode.
+func roundQuo(n, d *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	sign := int64(n.Sign() * d.Sign())
+	away := false
+	switch mode {
+	case RoundDown:
+	case RoundFloor:
+		away = sign < 0
+	case RoundCeiling:
+		away = sign > 0
+	default:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch cmp := half.Cmp(new(big.Int).Abs(d)); {
+		case cmp > 0:
+			away = true
+		case cmp == 0:
+			away = mode == RoundHalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(sign))
+	}
+	return q
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+func decAdd(args []Decimal, _ DecimalConfig) (Decimal, error) {
+	return boundedDecimal(args[0].Add(args[1]))
+}
+
+func decSub(args []Decimal, _ DecimalConfig) (Decimal, error) {
+	return boundedDecimal(args[0].Sub(args[1]))
+}
+
+func decMul(args []Decimal, cfg DecimalConfig) (Decimal, error) {
+	return cfg.Mul(args[0], args[1])
+}
+
+func decQuo(args []Decimal, cfg DecimalConfig) (Decimal, error) {
+	return cfg.Quo(args[0], args[1])
+}
+
+func decMin(args []Decimal, _ DecimalConfig) (Decimal, error) {
+	if args[0].Cmp(args[1]) <= 0 {
+		return args[0], nil
+	}
+	return args[1], nil
+}
+
+func decMax(args []Decimal, _ DecimalConfig) (Decimal, error) {
+	if args[0].Cmp(args[1]) >= 0 {
+		return args[0], nil
+	}
+	return args[1], nil
+}
+
+// toDecimal promotes an operand value to a decimal. Integers are converted
+// exactly; this is the only implicit conversion between number types.
+func (cfg DecimalConfig) toDecimal(v interface{}) Decimal {
+	switch val := v.(type) {
+	case Decimal:
+		return val
+	case *big.Int:
+		return cfg.FromInt(val)
+	default:
+		return cfg.FromInt(big.NewInt(v.(int64)))
+	}
+}
+
+// decimalVars returns the calc variables whose value is a decimal: those with
+// a decimal literal operand, a parameter bound to a decimal or an operand that
+// is itself a decimal variable.
+func (g *graph) decimalVars(instructions []Instruction, decimalParams map[string]bool) map[string]bool {
+	decimal := make(map[string]bool)
+	seen := make(map[string]bool)
+
+	var visit func(v string) bool
+	visit = func(v string) bool {
+		if seen[v] {
+			return decimal[v]
+		}
+		seen[v] = true
+		instr := instructions[g.index[v]]
+		for _, operand := range []interface{}{instr.Left, instr.Right} {
+			if s, ok := operand.(string); ok && (isDecimalLiteral(s) || isParam(s) && decimalParams[s[1:]]) {
+				decimal[v] = true
+			}
+		}
+		for _, dep := range g.deps[v] {
+			if visit(dep) {
+				decimal[v] = true
+			}
+		}
+		return decimal[v]
+	}
+
+	for _, v := range g.order {
+		visit(v)
+	}
+	return decimal
+}
+
+// checkDecimalOps rejects operations without a decimal implementation on
+// variables that are promoted to decimals.
+func (c *Calculator) checkDecimalOps(instructions []Instruction, g *graph, decimalParams map[string]bool) error {
+	decimal := g.decimalVars(instructions, decimalParams)
+	for _, v := range g.order {
+		if !decimal[v] {
+			continue
+		}
+		instr := instructions[g.index[v]]
+		if op, ok := c.registry.Lookup(instr.Op); ok && op.Decimal == nil {
+			return &ValidationError{Kind: UnsupportedOperation, Index: g.index[v], Var: v, Op: instr.Op, Reason: "decimal"}
+		}
+	}
+	return nil
+}
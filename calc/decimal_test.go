@@ -0,0 +1,157 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestDecimalParseAndFormat(t *testing.T) {
+	cfg := DecimalConfig{Scale: 3, Rounding: RoundHalfEven}
+	tests := []struct{ in, out string }{
+		{"12.345", "12.345"},
+		{"-0.5", "-0.500"},
+		{"+7", "7.000"},
+		{"0.001", "0.001"},
+	}
+	for _, tt := range tests {
+		d, err := cfg.Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if d.String() != tt.out {
+			t.Errorf("Parse(%q) = %s, expected %s", tt.in, d, tt.out)
+		}
+	}
+
+	if _, err := cfg.Parse("1.2345"); err == nil {
+		t.Error("expected error for literal with too many fractional digits")
+	}
+}
+
+func TestRoundQuo(t *testing.T) {
+	tests := []struct {
+		n, d     int64
+		mode     RoundingMode
+		expected int64
+	}{
+		{25, 10, RoundHalfEven, 2},
+		{35, 10, RoundHalfEven, 4},
+		{-25, 10, RoundHalfEven, -2},
+		{25, 10, RoundHalfUp, 3},
+		{-25, 10, RoundHalfUp, -3},
+		{29, 10, RoundDown, 2},
+		{-29, 10, RoundDown, -2},
+		{-21, 10, RoundFloor, -3},
+		{21, 10, RoundCeiling, 3},
+		{-21, 10, RoundCeiling, -2},
+		{26, -10, RoundHalfEven, -3},
+	}
+	for _, tt := range tests {
+		got := roundQuo(big.NewInt(tt.n), big.NewInt(tt.d), tt.mode)
+		if got.Int64() != tt.expected {
+			t.Errorf("%d / %d (%s): expected %d, got %s", tt.n, tt.d, tt.mode, tt.expected, got)
+		}
+	}
+}
+
+func TestDecimalCalculation(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "*", Var: "subtotal", Left: "19.99", Right: int64(3)},
+		{Type: "calc", Op: "*", Var: "tax", Left: "subtotal", Right: "0.0825"},
+		{Type: "calc", Op: "+", Var: "total", Left: "subtotal", Right: "tax"},
+		{Type: "calc", Op: "/", Var: "share", Left: "total", Right: int64(7)},
+		{Type: "calc", Op: "+", Var: "count", Left: int64(2), Right: int64(3)},
+		{Type: "calc", Op: "-", Var: "whole", Left: "1.50", Right: "0.5"},
+		{Type: "print", Var: "subtotal"},
+		{Type: "print", Var: "tax"},
+		{Type: "print", Var: "total"},
+		{Type: "print", Var: "share"},
+		{Type: "print", Var: "count"},
+		{Type: "print", Var: "whole"},
+	}
+
+	results, err := NewCalculator(WithDecimal(4, RoundHalfUp), WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	expected := []Result{
+		{Var: "subtotal", Exact: "59.9700"},
+		{Var: "tax", Exact: "4.9475"},
+		{Var: "total", Exact: "64.9175"},
+		{Var: "share", Exact: "9.2739"},
+		{Var: "count", Value: 5},
+		{Var: "whole", Value: 1, Exact: "1.0000"},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("expected %d results, got %d", len(expected), len(results))
+	}
+	for i, res := range results {
+		if res != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], res)
+		}
+	}
+}
+
+func TestDecimalResultLimit(t *testing.T) {
+	instructions := []Instruction{{Type: "calc", Op: "*", Var: "x0", Left: "99999.999999", Right: "99999.999999"}}
+	for i := 1; i < 24; i++ {
+		prev := fmt.Sprintf("x%d", i-1)
+		instructions = append(instructions, Instruction{Type: "calc", Op: "*", Var: fmt.Sprintf("x%d", i), Left: prev, Right: prev})
+	}
+	instructions = append(instructions, Instruction{Type: "print", Var: "x23"})
+	_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+	if !errors.Is(err, ErrResultTooLarge) {
+		t.Errorf("x * x: expected ErrResultTooLarge, got %v", err)
+	}
+
+	cfg := DefaultDecimalConfig
+	max := Decimal{unscaled: new(big.Int).Lsh(big.NewInt(1), maxBigBits-1), scale: cfg.Scale}
+	one := cfg.FromInt(big.NewInt(1))
+	if _, err := decAdd([]Decimal{max, max}, cfg); err != ErrResultTooLarge {
+		t.Errorf("+: expected ErrResultTooLarge, got %v", err)
+	}
+	if _, err := decSub([]Decimal{max, max}, cfg); err != nil {
+		t.Errorf("-: unexpected error %v", err)
+	}
+	if _, err := decMul([]Decimal{max, one}, cfg); err != nil {
+		t.Errorf("*: unexpected error %v", err)
+	}
+	if _, err := decMul([]Decimal{max, cfg.FromInt(big.NewInt(2))}, cfg); err != ErrResultTooLarge {
+		t.Errorf("*: expected ErrResultTooLarge, got %v", err)
+	}
+	if _, err := decQuo([]Decimal{max, Decimal{unscaled: big.NewInt(1), scale: cfg.Scale}}, cfg); err != ErrResultTooLarge {
+		t.Errorf("/: expected ErrResultTooLarge, got %v", err)
+	}
+}
+
+func TestDecimalValidation(t *testing.T) {
+	calc := NewCalculator(WithDecimal(2, RoundHalfEven), WithClock(NewVirtualClock(at(0))))
+
+	_, err := calc.Calculate([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: "1.005", Right: int64(1)},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Kind != InvalidLiteral {
+		t.Errorf("expected invalid literal error, got %v", err)
+	}
+
+	_, err = calc.Calculate([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: "1.5", Right: int64(1)},
+		{Type: "calc", Op: "%", Var: "y", Left: "x", Right: int64(2)},
+	})
+	if !errors.As(err, &verr) || verr.Kind != UnsupportedOperation || verr.Var != "y" {
+		t.Errorf("expected unsupported operation on y, got %v", err)
+	}
+
+	_, err = calc.Calculate([]Instruction{
+		{Type: "calc", Op: "/", Var: "x", Left: "1.5", Right: int64(0)},
+		{Type: "print", Var: "x"},
+	})
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected ErrDivisionByZero, got %v", err)
+	}
+}
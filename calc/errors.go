@@ -0,0 +1,89 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Code classifies the errors of a calculation for clients, see Classify.
+type Code string
+
+const (
+	CodeInvalidRequest     Code = "invalid_request"
+	CodeInvalidInstruction Code = "invalid_instruction"
+	CodeUndefinedVariable  Code = "undefined_variable"
+	CodeDuplicateVariable  Code = "duplicate_variable"
+	CodeUnknownOperation   Code = "unknown_operation"
+	CodeDependencyCycle    Code = "dependency_cycle"
+	CodeOverflow           Code = "overflow"
+	CodeDivisionByZero     Code = "division_by_zero"
+	CodeOperationFailed    Code = "operation_failed"
+	CodeTimeout            Code = "timeout"
+	CodeCanceled           Code = "canceled"
+	CodeSessionClosed      Code = "session_closed"
+)
+
+// UndefinedError reports a variable that is read but never defined.
+type UndefinedError struct {
+	Var string
+}
+
+func (e *UndefinedError) Error() string {
+	return fmt.Sprintf("variable %s not defined", e.Var)
+}
+
+// ErrorInfo is the classification of an error. Index and Var name the
+// offending calc instruction; Index is -1 if the error concerns none.
+type ErrorInfo struct {
+	Code  Code
+	Index int
+	Var   string
+}
+
+// Classify returns the ErrorInfo of an error returned by this package. Errors
+// it does not know, such as malformed instructions or parameters, are
+// CodeInvalidRequest.
+func Classify(err error) ErrorInfo {
+	var (
+		ve *ValidationError
+		oe *OperationError
+		ue *UndefinedError
+	)
+	switch {
+	case errors.As(err, &ve):
+		return ErrorInfo{Code: validationCode(ve.Kind), Index: ve.Index, Var: ve.Var}
+	case errors.As(err, &oe):
+		code := CodeOperationFailed
+		switch {
+		case errors.Is(oe.Err, ErrOverflow), errors.Is(oe.Err, ErrResultTooLarge):
+			code = CodeOverflow
+		case errors.Is(oe.Err, ErrDivisionByZero):
+			code = CodeDivisionByZero
+		}
+		return ErrorInfo{Code: code, Index: oe.Index, Var: oe.Var}
+	case errors.As(err, &ue):
+		return ErrorInfo{Code: CodeUndefinedVariable, Index: -1, Var: ue.Var}
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorInfo{Code: CodeTimeout, Index: -1}
+	case errors.Is(err, context.Canceled):
+		return ErrorInfo{Code: CodeCanceled, Index: -1}
+	case errors.Is(err, ErrSessionClosed):
+		return ErrorInfo{Code: CodeSessionClosed, Index: -1}
+	}
+	return ErrorInfo{Code: CodeInvalidRequest, Index: -1}
+}
+
+func validationCode(kind ValidationKind) Code {
+	switch kind {
+	case UndefinedReference:
+		return CodeUndefinedVariable
+	case DuplicateVariable:
+		return CodeDuplicateVariable
+	case UnknownOperation:
+		return CodeUnknownOperation
+	case DependencyCycle, SelfReference:
+		return CodeDependencyCycle
+	}
+	return CodeInvalidInstruction
+}
@@ -0,0 +1,75 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	c := NewCalculator(WithClock(NewVirtualClock(at(0))), WithOverflowPolicy(OverflowError))
+	tests := []struct {
+		name         string
+		instructions []Instruction
+		expected     ErrorInfo
+	}{
+		{"undefined", []Instruction{
+			{Type: "calc", Op: "+", Var: "x", Left: "y", Right: int64(1)},
+		}, ErrorInfo{Code: CodeUndefinedVariable, Index: 0, Var: "x"}},
+		{"duplicate", []Instruction{
+			{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(1)},
+			{Type: "calc", Op: "+", Var: "x", Left: int64(2), Right: int64(2)},
+		}, ErrorInfo{Code: CodeDuplicateVariable, Index: 1, Var: "x"}},
+		{"unknown operation", []Instruction{
+			{Type: "print", Var: "x"},
+			{Type: "calc", Op: "pow", Var: "x", Left: int64(1), Right: int64(1)},
+		}, ErrorInfo{Code: CodeUnknownOperation, Index: 1, Var: "x"}},
+		{"cycle", []Instruction{
+			{Type: "calc", Op: "+", Var: "x", Left: "y", Right: int64(1)},
+			{Type: "calc", Op: "+", Var: "y", Left: "x", Right: int64(1)},
+		}, ErrorInfo{Code: CodeDependencyCycle, Index: 0, Var: "x"}},
+		{"overflow", []Instruction{
+			{Type: "calc", Op: "*", Var: "x", Left: int64(1 << 62), Right: int64(4)},
+			{Type: "print", Var: "x"},
+		}, ErrorInfo{Code: CodeOverflow, Index: 0, Var: "x"}},
+		{"division by zero", []Instruction{
+			{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(1)},
+			{Type: "calc", Op: "/", Var: "y", Left: "x", Right: int64(0)},
+			{Type: "print", Var: "y"},
+		}, ErrorInfo{Code: CodeDivisionByZero, Index: 1, Var: "y"}},
+		{"malformed", []Instruction{
+			{Type: "jump", Var: "x"},
+		}, ErrorInfo{Code: CodeInvalidRequest, Index: -1}},
+	}
+	for _, tt := range tests {
+		_, err := c.Calculate(tt.instructions)
+		if got := Classify(err); got != tt.expected {
+			t.Errorf("%s: expected %+v, got %+v (%v)", tt.name, tt.expected, got, err)
+		}
+	}
+
+	clock := NewVirtualClock(at(0))
+	ctx, stop := virtualTimeout(clock, time.Millisecond)
+	_, err := NewCalculator(WithClock(clock)).CalculateContext(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(1)},
+		{Type: "print", Var: "x"},
+	})
+	stop()
+	var ie *IncompleteError
+	if !errors.As(err, &ie) || Classify(err).Code != CodeTimeout {
+		t.Errorf("expected timeout, got %+v (%v)", Classify(err), err)
+	}
+}
+
+func TestClassifySession(t *testing.T) {
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(context.Background(), RunOptions{})
+	f, _ := s.Submit(Instruction{Type: "print", Var: "never"})
+	s.Close()
+	if _, err := f.Result(); Classify(err) != (ErrorInfo{Code: CodeUndefinedVariable, Index: -1, Var: "never"}) {
+		t.Errorf("expected undefined variable, got %+v (%v)", Classify(err), err)
+	}
+	if _, err := s.Submit(Instruction{Type: "print", Var: "x"}); Classify(err).Code != CodeSessionClosed {
+		t.Errorf("expected session closed, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package calc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// LatencyModel gives the simulated duration of an operation. Latency is
+// called for every execution with the operand values; Estimate predicts it
+// before the operands are known and is used for planning.
+type LatencyModel interface {
+	Latency(args []interface{}) time.Duration
+	Estimate(arity int) time.Duration
+}
+
+// ConstantLatency takes the same time on every execution.
+type ConstantLatency time.Duration
+
+func (l ConstantLatency) Latency([]interface{}) time.Duration {
+	return time.Duration(l)
+}
+
+func (l ConstantLatency) Estimate(int) time.Duration {
+	return time.Duration(l)
+}
+
+// SizeLatency grows with the operands: Base plus PerBit for every bit of
+// their magnitudes. The estimate assumes full 64-bit operands.
+type SizeLatency struct {
+	Base   time.Duration
+	PerBit time.Duration
+}
+
+func (l SizeLatency) Latency(args []interface{}) time.Duration {
+	n := 0
+	for _, a := range args {
+		n += bitLen(a)
+	}
+	return l.Base + time.Duration(n)*l.PerBit
+}
+
+func (l SizeLatency) Estimate(arity int) time.Duration {
+	return l.Base + time.Duration(64*arity)*l.PerBit
+}
+
+func bitLen(v interface{}) int {
+	switch val := v.(type) {
+	case int64:
+		if val < 0 {
+			return bits.Len64(uint64(-val))
+		}
+		return bits.Len64(uint64(val))
+	case *big.Int:
+		return val.BitLen()
+	case Decimal:
+		return val.unscaled.BitLen()
+	}
+	return 0
+}
+
+type Distribution string
+
+const (
+	// Uniform draws from [Mean-Jitter, Mean+Jitter].
+	Uniform Distribution = "uniform"
+	// Exponential draws from the exponential distribution with mean Mean.
+	Exponential Distribution = "exponential"
+)
+
+// RandomLatency draws every latency from a distribution. Its source is
+// seeded, so a sequence of executions is reproducible.
+type RandomLatency struct {
+	Distribution Distribution
+	Mean         time.Duration
+	Jitter       time.Duration
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func NewRandomLatency(dist Distribution, mean, jitter time.Duration, seed int64) (*RandomLatency, error) {
+	if dist != Uniform && dist != Exponential {
+		return nil, fmt.Errorf("unknown distribution %q", dist)
+	}
+	if mean < 0 || jitter < 0 || jitter > mean {
+		return nil, fmt.Errorf("invalid random latency: mean %v, jitter %v", mean, jitter)
+	}
+	return &RandomLatency{
+		Distribution: dist,
+		Mean:         mean,
+		Jitter:       jitter,
+		rng:          rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+func (l *RandomLatency) Latency([]interface{}) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.Distribution == Exponential {
+		return time.Duration(l.rng.ExpFloat64() * float64(l.Mean))
+	}
+	if l.Jitter == 0 {
+		return l.Mean
+	}
+	return l.Mean - l.Jitter + time.Duration(l.rng.Int63n(int64(2*l.Jitter)+1))
+}
+
+func (l *RandomLatency) Estimate(int) time.Duration {
+	return l.Mean
+}
+
+// LatencyConfig describes a latency model in configuration files. Model is
+// "constant" (Latency), "size" (Base, PerBit) or "random" (Distribution,
+// Mean, Jitter, Seed); durations are written like "50ms".
+type LatencyConfig struct {
+	Model        string `json:"model"`
+	Latency      string `json:"latency,omitempty"`
+	Base         string `json:"base,omitempty"`
+	PerBit       string `json:"per_bit,omitempty"`
+	Distribution string `json:"distribution,omitempty"`
+	Mean         string `json:"mean,omitempty"`
+	Jitter       string `json:"jitter,omitempty"`
+	Seed         int64  `json:"seed,omitempty"`
+}
+
+func (c LatencyConfig) Build() (LatencyModel, error) {
+	var err error
+	duration := func(s string) time.Duration {
+		if s == "" || err != nil {
+			return 0
+		}
+		var d time.Duration
+		if d, err = time.ParseDuration(s); err == nil && d < 0 {
+			err = fmt.Errorf("negative duration %s", s)
+		}
+		return d
+	}
+
+	var model LatencyModel
+	switch c.Model {
+	case "constant":
+		model = ConstantLatency(duration(c.Latency))
+	case "size":
+		model = SizeLatency{Base: duration(c.Base), PerBit: duration(c.PerBit)}
+	case "random":
+		dist := Distribution(c.Distribution)
+		if dist == "" {
+			dist = Uniform
+		}
+		mean, jitter := duration(c.Mean), duration(c.Jitter)
+		if err == nil {
+			model, err = NewRandomLatency(dist, mean, jitter, c.Seed)
+		}
+	default:
+		return nil, fmt.Errorf("unknown latency model %q", c.Model)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// LoadLatencyConfig reads a JSON object mapping operation names to latency
+// models and applies it to r.
+func LoadLatencyConfig(rd io.Reader, r *OperationRegistry) error {
+	var configs map[string]LatencyConfig
+	if err := json.NewDecoder(rd).Decode(&configs); err != nil {
+		return err
+	}
+	for name, config := range configs {
+		model, err := config.Build()
+		if err != nil {
+			return fmt.Errorf("operation %s: %w", name, err)
+		}
+		if err := r.SetLatency(name, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package calc
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyModels(t *testing.T) {
+	if d := ConstantLatency(5 * time.Millisecond).Latency(nil); d != 5*time.Millisecond {
+		t.Errorf("constant latency = %v", d)
+	}
+
+	size := SizeLatency{Base: time.Millisecond, PerBit: time.Microsecond}
+	args := []interface{}{int64(-255), new(big.Int).Lsh(big.NewInt(1), 99)}
+	if d := size.Latency(args); d != time.Millisecond+108*time.Microsecond {
+		t.Errorf("size latency = %v", d)
+	}
+	if d := size.Estimate(2); d != time.Millisecond+128*time.Microsecond {
+		t.Errorf("size estimate = %v", d)
+	}
+
+	a, _ := NewRandomLatency(Uniform, 10*time.Millisecond, 2*time.Millisecond, 42)
+	b, _ := NewRandomLatency(Uniform, 10*time.Millisecond, 2*time.Millisecond, 42)
+	for i := 0; i < 100; i++ {
+		da, db := a.Latency(nil), b.Latency(nil)
+		if da != db {
+			t.Fatalf("same seed produced %v and %v", da, db)
+		}
+		if da < 8*time.Millisecond || da > 12*time.Millisecond {
+			t.Fatalf("uniform latency %v out of range", da)
+		}
+	}
+	if _, err := NewRandomLatency("normal", time.Millisecond, 0, 1); err == nil {
+		t.Error("expected error for unknown distribution")
+	}
+}
+
+func TestLoadLatencyConfig(t *testing.T) {
+	r := DefaultRegistry()
+	err := LoadLatencyConfig(strings.NewReader(`{
+		"+": {"model": "constant", "latency": "5ms"},
+		"*": {"model": "size", "base": "20ms", "per_bit": "100us"},
+		"/": {"model": "random", "distribution": "exponential", "mean": "30ms", "seed": 7}
+	}`), r)
+	if err != nil {
+		t.Fatalf("LoadLatencyConfig failed: %v", err)
+	}
+
+	expected := map[string]time.Duration{
+		"+": 5 * time.Millisecond,
+		"*": 20*time.Millisecond + 128*100*time.Microsecond,
+		"/": 30 * time.Millisecond,
+		"-": defaultCost,
+	}
+	for name, d := range expected {
+		op, _ := r.Lookup(name)
+		if got := op.Latency.Estimate(op.Arity); got != d {
+			t.Errorf("%s: expected estimate %v, got %v", name, d, got)
+		}
+	}
+
+	for _, config := range []string{
+		`{"+": {"model": "linear"}}`,
+		`{"+": {"model": "constant", "latency": "-1ms"}}`,
+		`{"+": {"model": "random", "mean": "1ms", "jitter": "2ms"}}`,
+		`{"nope": {"model": "constant", "latency": "1ms"}}`,
+	} {
+		if err := LoadLatencyConfig(strings.NewReader(config), DefaultRegistry()); err == nil {
+			t.Errorf("expected error for %s", config)
+		}
+	}
+}
+
+func TestProgramEstimate(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("+", 2, add, 10*time.Millisecond)
+	r.Register("*", 2, mul, 30*time.Millisecond)
+
+	instructions := []Instruction{
+		{Type: "calc", Op: "*", Var: "a", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "+", Var: "b", Left: "a", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "c", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "d", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "*", Var: "unused", Left: int64(1), Right: int64(1)},
+		{Type: "print", Var: "b"},
+		{Type: "print", Var: "c"},
+		{Type: "print", Var: "d"},
+	}
+
+	tests := []struct {
+		workers  int
+		expected time.Duration
+	}{
+		{0, 40 * time.Millisecond},
+		{1, 60 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		p, err := NewCalculator(WithRegistry(r), WithWorkers(tt.workers)).Prepare(instructions)
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if got := p.Estimate(); got != tt.expected {
+			t.Errorf("workers %d: expected %v, got %v", tt.workers, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package calc
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// literalValue converts a literal operand into an int64, or into a *big.Int
+// when bigInt is set. Literals must denote an exact integer: 2.0 and 1e3 are
+// accepted, 2.9 is not, and nothing is silently truncated.
+func literalValue(v interface{}, bigInt bool) (interface{}, error) {
+	var n *big.Int
+	switch val := v.(type) {
+	case int64:
+		if bigInt {
+			return big.NewInt(val), nil
+		}
+		return val, nil
+	case float64:
+		if math.IsInf(val, 0) || math.IsNaN(val) || val != math.Trunc(val) {
+			return nil, fmt.Errorf("%v is not an integer", val)
+		}
+		n, _ = big.NewFloat(val).Int(nil)
+	case json.Number:
+		r, ok := new(big.Rat).SetString(string(val))
+		if !ok {
+			return nil, fmt.Errorf("%s is not a number", val)
+		}
+		if !r.IsInt() {
+			return nil, fmt.Errorf("%s is not an integer (pass decimals as strings)", val)
+		}
+		n = r.Num()
+	case string:
+		var ok bool
+		if n, ok = new(big.Int).SetString(val, 10); !ok {
+			return nil, fmt.Errorf("%s is not an integer", val)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operand type %T", v)
+	}
+
+	if bigInt {
+		return n, nil
+	}
+	if !n.IsInt64() {
+		return nil, fmt.Errorf("%s is out of int64 range", n)
+	}
+	return n.Int64(), nil
+}
+
+// checkLiterals reports the first literal operand that cannot be used in the
+// requested number mode or decimal format.
+func checkLiterals(instructions []Instruction, bigInt bool, cfg DecimalConfig) error {
+	for i, instr := range instructions {
+		if instr.Type != "calc" {
+			continue
+		}
+		for _, operand := range []interface{}{instr.Left, instr.Right} {
+			if operand == nil || isVariable(operand) || isParam(operand) {
+				continue
+			}
+			var err error
+			if s, ok := operand.(string); ok && isDecimalLiteral(s) {
+				_, err = cfg.Parse(s)
+			} else {
+				_, err = literalValue(operand, bigInt)
+			}
+			if err != nil {
+				return &ValidationError{Kind: InvalidLiteral, Index: i, Var: instr.Var, Reason: err.Error()}
+			}
+		}
+	}
+	return nil
+}
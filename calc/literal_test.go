@@ -0,0 +1,91 @@
+package calc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func decodeInstructions(t *testing.T, raw string) []Instruction {
+	t.Helper()
+	var instructions []Instruction
+	decoder := json.NewDecoder(strings.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&instructions); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	return instructions
+}
+
+func TestJSONNumberLiterals(t *testing.T) {
+	instructions := decodeInstructions(t, `[
+		{ "type": "calc", "op": "-", "var": "x", "left": 9223372036854775807, "right": 0 },
+		{ "type": "calc", "op": "+", "var": "y", "left": -9223372036854775808, "right": 2.0 },
+		{ "type": "calc", "op": "*", "var": "z", "left": 1e3, "right": 1 },
+		{ "type": "print", "var": "x" },
+		{ "type": "print", "var": "y" },
+		{ "type": "print", "var": "z" }
+	]`)
+
+	results, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	expected := []int64{math.MaxInt64, math.MinInt64 + 2, 1000}
+	for i, res := range results {
+		if res.Value != expected[i] {
+			t.Errorf("for %s expected %d, got %d", res.Var, expected[i], res.Value)
+		}
+	}
+}
+
+func TestInvalidLiterals(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"fraction", `[{ "type": "print", "var": "x" }, { "type": "calc", "op": "+", "var": "x", "left": 1, "right": 2.9 }]`},
+		{"out of range", `[{ "type": "print", "var": "x" }, { "type": "calc", "op": "+", "var": "x", "left": 9223372036854775808, "right": 0 }]`},
+		{"string out of range", `[{ "type": "print", "var": "x" }, { "type": "calc", "op": "+", "var": "x", "left": "-9223372036854775809", "right": 0 }]`},
+		{"bool operand", `[{ "type": "print", "var": "x" }, { "type": "calc", "op": "+", "var": "x", "left": true, "right": 0 }]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate(decodeInstructions(t, tt.raw))
+			var verr *ValidationError
+			if !errors.As(err, &verr) || verr.Kind != InvalidLiteral {
+				t.Fatalf("expected invalid literal error, got %v", err)
+			}
+			if verr.Index != 1 || verr.Var != "x" {
+				t.Errorf("expected instruction 1 (x), got %d (%s)", verr.Index, verr.Var)
+			}
+		})
+	}
+
+	_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: 2.5, Right: int64(1)},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Kind != InvalidLiteral {
+		t.Errorf("expected invalid literal error for float64 operand, got %v", err)
+	}
+}
+
+func TestBigIntJSONLiterals(t *testing.T) {
+	instructions := decodeInstructions(t, `[
+		{ "type": "calc", "op": "+", "var": "x", "left": 99999999999999999999999, "right": 1 },
+		{ "type": "print", "var": "x" }
+	]`)
+
+	report, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Execute(context.Background(), instructions, RunOptions{BigInt: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if report.Results[0].Exact != "100000000000000000000000" {
+		t.Errorf("expected exact 100000000000000000000000, got %s", report.Results[0].Exact)
+	}
+}
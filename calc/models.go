@@ -1,10 +1,15 @@
 package calc
 
-import "sync"
+import "time"
 
+// Calculator holds the configuration of the engine. It is never modified by
+// a calculation and is safe to share between concurrent requests.
 type Calculator struct {
-	vars   sync.Map
-	ready  map[string]*sync.WaitGroup
+	registry *OperationRegistry
+	overflow OverflowPolicy
+	decimal  DecimalConfig
+	workers  int
+	clock    Clock
 }
 
 type Instruction struct {
@@ -15,7 +20,107 @@ type Instruction struct {
 	Right interface{} `json:"right,omitempty"`
 }
 
+// Result is the value of a printed variable. For big integers and decimals
+// Exact holds the exact decimal representation and Value is only set if the
+// number is an integer that fits in int64.
 type Result struct {
 	Var   string `json:"var"`
 	Value int64  `json:"value"`
-}
\ No newline at end of file
+	Exact string `json:"exact,omitempty"`
+	// Version is the version of Var printed in versioned batches.
+	Version int `json:"version,omitempty"`
+	// Missing is set for a print that has no value, with Error telling why:
+	// its variable is never defined or, in program order, only after it.
+	Missing bool   `json:"missing,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+type Status string
+
+const (
+	StatusOK       Status = "ok"
+	StatusFailed   Status = "failed"
+	StatusSkipped  Status = "skipped"
+	StatusCanceled Status = "canceled"
+	StatusPruned   Status = "pruned"
+	StatusPending  Status = "pending"
+)
+
+// InstructionStatus is the outcome of a single calc instruction. Skipped
+// instructions were never executed because one of their dependencies failed,
+// canceled ones were interrupted by the calculation context and pruned ones
+// were not needed by any print. Pending is only reported for the variables
+// of a Session that are not computed yet.
+type InstructionStatus struct {
+	Index  int
+	Var    string
+	Status Status
+	Err    error
+}
+
+type Report struct {
+	Results  []Result
+	Statuses []InstructionStatus
+	Pruned   int
+	// Started is the time the run began and Trace, if requested with
+	// RunOptions.Trace, the spans of the instructions that were executed.
+	Started time.Time
+	Trace   []Span
+}
+
+// Span is the execution trace of one calc instruction. Worker numbers the
+// concurrently running operations from 0. Queued is the time the
+// instruction was ready but waited for a free worker.
+type Span struct {
+	Index  int
+	Var    string
+	Op     string
+	Status Status
+	Worker int
+	Start  time.Time
+	End    time.Time
+	Queued time.Duration
+	Waits  []Wait
+}
+
+// Wait is the time an instruction was blocked on one dependency. The time
+// from the start of the run until the instruction was ready is split among
+// its dependencies in the order they finished, each being charged the time
+// after the previous one finished.
+type Wait struct {
+	Dep      string
+	Duration time.Duration
+}
+
+type PrintMode string
+
+const (
+	// PrintFinal answers every print with the value of its variable,
+	// wherever the variable is defined in the batch.
+	PrintFinal PrintMode = "final"
+	// PrintProgramOrder answers a print only if its variable is defined
+	// before it; a print ahead of the definition yields a missing result.
+	PrintProgramOrder PrintMode = "program"
+)
+
+// RunOptions tune a single call to Calculator.Execute.
+type RunOptions struct {
+	// FullEvaluation executes every calc instruction, including those whose
+	// result is never printed.
+	FullEvaluation bool
+	// BigInt computes with arbitrary-precision integers. Integer literals may
+	// then also be given as decimal strings of any length.
+	BigInt bool
+	// Trace records a Span for every executed instruction in Report.Trace.
+	Trace bool
+	// PrintMode selects which value a print reports, PrintFinal by default.
+	PrintMode PrintMode
+	// Versioned lets a batch assign a variable several times, each
+	// assignment defining a new version x#1, x#2, ... Operands and prints
+	// refer to the latest version assigned before them, and Results report
+	// the version printed.
+	Versioned bool
+	// Reactive lets the calcs of a Session redefine variables, see
+	// Calculator.NewSession.
+	Reactive bool
+}
@@ -0,0 +1,184 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrDivisionByZero   = errors.New("division by zero")
+	ErrNegativeExponent = errors.New("negative exponent")
+	ErrOverflow         = errors.New("integer overflow")
+)
+
+// OperationError reports a calc instruction whose operation failed.
+type OperationError struct {
+	Index int
+	Var   string
+	Op    string
+	Args  []interface{}
+	Err   error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("instruction %d: %s = %s: %v", e.Index, e.Var, formatCall(e.Op, e.Args), e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// formatCall renders symbolic binary operations infix and everything else in
+// function call form, e.g. "7 / 0" or "min(1, 2)".
+func formatCall(op string, args []interface{}) string {
+	parts := make([]string, len(args))
+	for i, a := range args {
+		parts[i] = fmt.Sprint(a)
+	}
+	if len(args) == 2 && !strings.ContainsFunc(op, unicode.IsLetter) {
+		return parts[0] + " " + op + " " + parts[1]
+	}
+	return op + "(" + strings.Join(parts, ", ") + ")"
+}
+
+// call runs fn, turning a panic in a custom operation into an error.
+func call[T any](fn func([]T) (T, error), args []T) (val T, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("operation panicked: %v", r)
+		}
+	}()
+	return fn(args)
+}
+
+// overflow is returned by the built-in operations when the exact result does
+// not fit in int64. wrapped is the two's complement result and positive tells
+// on which side the exact result left the int64 range.
+type overflow struct {
+	wrapped  int64
+	positive bool
+}
+
+func (o *overflow) Error() string {
+	return ErrOverflow.Error()
+}
+
+func (o *overflow) Is(target error) bool {
+	return target == ErrOverflow
+}
+
+// resolve applies the overflow policy to an overflowed result.
+func (o *overflow) resolve(policy OverflowPolicy) (int64, error) {
+	switch policy {
+	case OverflowWrap:
+		return o.wrapped, nil
+	case OverflowSaturate:
+		if o.positive {
+			return math.MaxInt64, nil
+		}
+		return math.MinInt64, nil
+	}
+	return 0, ErrOverflow
+}
+
+func addChecked(a, b int64) (int64, bool) {
+	s := a + b
+	return s, (a > 0 && b > 0 && s < 0) || (a < 0 && b < 0 && s >= 0)
+}
+
+func subChecked(a, b int64) (int64, bool) {
+	d := a - b
+	return d, (a >= 0 && b < 0 && d < 0) || (a < 0 && b > 0 && d >= 0)
+}
+
+func mulChecked(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, false
+	}
+	p := a * b
+	return p, p/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64)
+}
+
+func add(args []int64) (int64, error) {
+	s, ok := addChecked(args[0], args[1])
+	if ok {
+		return 0, &overflow{wrapped: s, positive: args[0] > 0}
+	}
+	return s, nil
+}
+
+func sub(args []int64) (int64, error) {
+	d, ok := subChecked(args[0], args[1])
+	if ok {
+		return 0, &overflow{wrapped: d, positive: args[0] >= 0}
+	}
+	return d, nil
+}
+
+func mul(args []int64) (int64, error) {
+	p, ok := mulChecked(args[0], args[1])
+	if ok {
+		return 0, &overflow{wrapped: p, positive: (args[0] > 0) == (args[1] > 0)}
+	}
+	return p, nil
+}
+
+// div truncates toward zero. math.MinInt64 / -1 does not fit in int64 and is
+// reported as an overflow.
+func div(args []int64) (int64, error) {
+	a, b := args[0], args[1]
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+	if a == math.MinInt64 && b == -1 {
+		return 0, &overflow{wrapped: math.MinInt64, positive: true}
+	}
+	return a / b, nil
+}
+
+// mod returns the remainder of div, which takes the sign of the dividend.
+func mod(args []int64) (int64, error) {
+	a, b := args[0], args[1]
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+	if b == -1 {
+		return 0, nil
+	}
+	return a % b, nil
+}
+
+// pow raises the left operand to a non-negative integer power; 0 ** 0 is 1.
+func pow(args []int64) (int64, error) {
+	base, exp := args[0], args[1]
+	if exp < 0 {
+		return 0, ErrNegativeExponent
+	}
+	negative := base < 0 && exp&1 == 1
+
+	result := int64(1)
+	overflowed := false
+	for exp > 0 {
+		var o bool
+		if exp&1 == 1 {
+			result, o = mulChecked(result, base)
+			overflowed = overflowed || o
+		}
+		exp >>= 1
+		if exp > 0 {
+			base, o = mulChecked(base, base)
+			overflowed = overflowed || o
+		}
+	}
+	if overflowed {
+		return 0, &overflow{wrapped: result, positive: !negative}
+	}
+	return result, nil
+}
+
+func minOp(args []int64) (int64, error) { return min(args[0], args[1]), nil }
+
+func maxOp(args []int64) (int64, error) { return max(args[0], args[1]), nil }
@@ -0,0 +1,144 @@
+package calc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBuiltinOperations(t *testing.T) {
+	tests := []struct {
+		fn       OpFunc
+		a, b     int64
+		expected int64
+		err      error
+	}{
+		{div, 7, 2, 3, nil},
+		{div, -7, 2, -3, nil},
+		{div, 7, 0, 0, ErrDivisionByZero},
+		{div, math.MinInt64, -1, 0, ErrOverflow},
+		{mod, -7, 3, -1, nil},
+		{mod, 7, 0, 0, ErrDivisionByZero},
+		{mod, math.MinInt64, -1, 0, nil},
+		{pow, 2, 10, 1024, nil},
+		{pow, -3, 3, -27, nil},
+		{pow, 0, 0, 1, nil},
+		{pow, 2, -1, 0, ErrNegativeExponent},
+		{minOp, 3, -4, -4, nil},
+		{maxOp, 3, -4, 3, nil},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fn([]int64{tt.a, tt.b})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("(%d, %d): expected error %v, got %v", tt.a, tt.b, tt.err, err)
+			continue
+		}
+		if err == nil && got != tt.expected {
+			t.Errorf("(%d, %d): expected %d, got %d", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
+
+func TestOperationErrorNamesInstruction(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "-", Var: "zero", Left: "x", Right: int64(3)},
+		{Type: "calc", Op: "/", Var: "y", Left: "x", Right: "zero"},
+		{Type: "print", Var: "y"},
+	}
+
+	_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+	var opErr *OperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected OperationError, got %v", err)
+	}
+	if !errors.Is(err, ErrDivisionByZero) {
+		t.Errorf("expected ErrDivisionByZero, got %v", err)
+	}
+	if opErr.Index != 2 || opErr.Var != "y" {
+		t.Errorf("expected instruction 2 (y), got %d (%s)", opErr.Index, opErr.Var)
+	}
+	if got := opErr.Error(); got != "instruction 2: y = 3 / 0: division by zero" {
+		t.Errorf("unexpected message %q", got)
+	}
+}
+
+func TestPanickingOperation(t *testing.T) {
+	registry := DefaultRegistry()
+	registry.Register("boom", 1, func([]int64) (int64, error) { panic("boom") }, 0)
+
+	_, err := NewCalculator(WithRegistry(registry), WithClock(NewVirtualClock(at(0)))).Calculate([]Instruction{
+		{Type: "calc", Op: "boom", Var: "x", Left: int64(1)},
+		{Type: "print", Var: "x"},
+	})
+	var opErr *OperationError
+	if !errors.As(err, &opErr) {
+		t.Fatalf("expected OperationError, got %v", err)
+	}
+}
+
+func TestOverflowPolicies(t *testing.T) {
+	tests := []struct {
+		op          string
+		left, right int64
+		wrap        int64
+		saturate    int64
+	}{
+		{"+", math.MaxInt64, 1, math.MinInt64, math.MaxInt64},
+		{"-", math.MinInt64, 1, math.MaxInt64, math.MinInt64},
+		{"*", math.MaxInt64, -2, 2, math.MinInt64},
+		{"/", math.MinInt64, -1, math.MinInt64, math.MaxInt64},
+		{"**", -3, 41, 420491770248316829, math.MinInt64},
+		{"**", 2, 64, 0, math.MaxInt64},
+	}
+
+	for _, tt := range tests {
+		instructions := []Instruction{
+			{Type: "calc", Op: tt.op, Var: "x", Left: tt.left, Right: tt.right},
+			{Type: "print", Var: "x"},
+		}
+
+		results, err := NewCalculator(WithOverflowPolicy(OverflowWrap), WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+		if err != nil || results[0].Value != tt.wrap {
+			t.Errorf("%d %s %d wrap: expected %d, got %v (err %v)", tt.left, tt.op, tt.right, tt.wrap, results, err)
+		}
+
+		results, err = NewCalculator(WithOverflowPolicy(OverflowSaturate), WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+		if err != nil || results[0].Value != tt.saturate {
+			t.Errorf("%d %s %d saturate: expected %d, got %v (err %v)", tt.left, tt.op, tt.right, tt.saturate, results, err)
+		}
+
+		_, err = NewCalculator(WithOverflowPolicy(OverflowError), WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+		var opErr *OperationError
+		if !errors.As(err, &opErr) || !errors.Is(err, ErrOverflow) {
+			t.Errorf("%d %s %d error: expected overflow OperationError, got %v", tt.left, tt.op, tt.right, err)
+			continue
+		}
+		if opErr.Index != 0 || opErr.Args[0] != tt.left || opErr.Args[1] != tt.right {
+			t.Errorf("%d %s %d error: unexpected details %+v", tt.left, tt.op, tt.right, opErr)
+		}
+	}
+}
+
+func TestCheckedArithmeticWithoutOverflow(t *testing.T) {
+	tests := []struct {
+		fn       OpFunc
+		a, b     int64
+		expected int64
+	}{
+		{add, math.MaxInt64, math.MinInt64, -1},
+		{sub, -1, math.MinInt64, math.MaxInt64},
+		{mul, math.MinInt64, 1, math.MinInt64},
+		{mul, -1, math.MaxInt64, -math.MaxInt64},
+		{pow, -2, 63, math.MinInt64},
+		{pow, 3, 39, 4052555153018976267},
+	}
+
+	for _, tt := range tests {
+		got, err := tt.fn([]int64{tt.a, tt.b})
+		if err != nil || got != tt.expected {
+			t.Errorf("(%d, %d): expected %d, got %d (err %v)", tt.a, tt.b, tt.expected, got, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Plan describes how a program is executed: its dependency graph, the
+// levels of instructions that can run concurrently and its critical path.
+type Plan struct {
+	Nodes []PlanNode
+	// Levels groups the scheduled variables by depth: those of level 0 have
+	// no dependencies and those of level i depend on some of level i-1.
+	Levels [][]string
+	// CriticalPath is the chain of operations with the longest estimated
+	// latency, which bounds the duration of a run from below.
+	CriticalPath       []string
+	CriticalPathLength time.Duration
+	// Estimate is the predicted duration of a run with the worker limit.
+	Estimate time.Duration
+	Pruned   []string
+}
+
+type PlanNode struct {
+	Index    int
+	Var      string
+	Expr     string
+	Deps     []string
+	Level    int
+	Estimate time.Duration
+	Pruned   bool
+}
+
+// Plan explains how p is executed. Pruned instructions have level -1.
+func (p *Program) Plan() *Plan {
+	plan := &Plan{Estimate: p.Estimate()}
+	level := make(map[string]int)
+	var depth func(v string) int
+	depth = func(v string) int {
+		if l, ok := level[v]; ok {
+			return l
+		}
+		l := 0
+		for _, dep := range p.graph.deps[v] {
+			l = max(l, depth(dep)+1)
+		}
+		level[v] = l
+		return l
+	}
+
+	for _, instr := range p.calcOps {
+		v := instr.Var
+		n := PlanNode{
+			Index: p.graph.index[v],
+			Var:   v,
+			Expr:  formatExpr(instr),
+			Deps:  append([]string{}, p.graph.deps[v]...),
+			Level: -1,
+		}
+		if p.needed != nil && !p.needed[v] {
+			n.Pruned = true
+			op, _ := p.calc.registry.Lookup(instr.Op)
+			n.Estimate = op.Latency.Estimate(op.Arity)
+			plan.Pruned = append(plan.Pruned, v)
+		} else {
+			n.Level = depth(v)
+			n.Estimate = p.cost[v]
+			for len(plan.Levels) <= n.Level {
+				plan.Levels = append(plan.Levels, nil)
+			}
+			plan.Levels[n.Level] = append(plan.Levels[n.Level], v)
+		}
+		plan.Nodes = append(plan.Nodes, n)
+	}
+
+	next := func(candidates []string) string {
+		best := ""
+		for _, v := range candidates {
+			if best == "" || p.priority[v] > p.priority[best] ||
+				p.priority[v] == p.priority[best] && p.graph.index[v] < p.graph.index[best] {
+				best = v
+			}
+		}
+		return best
+	}
+	var roots []string
+	if len(plan.Levels) > 0 {
+		roots = plan.Levels[0]
+	}
+	for v := next(roots); v != ""; v = next(p.dependents[v]) {
+		plan.CriticalPath = append(plan.CriticalPath, v)
+	}
+	if len(plan.CriticalPath) > 0 {
+		plan.CriticalPathLength = p.priority[plan.CriticalPath[0]]
+	}
+	return plan
+}
+
+// formatExpr renders the operation of a calc instruction: infix for
+// symbolic operators, as a call otherwise.
+func formatExpr(instr Instruction) string {
+	var operands []string
+	for _, operand := range []interface{}{instr.Left, instr.Right} {
+		if operand != nil {
+			operands = append(operands, fmt.Sprint(operand))
+		}
+	}
+	if len(operands) == 2 && !strings.ContainsFunc(instr.Op, unicode.IsLetter) {
+		return operands[0] + " " + instr.Op + " " + operands[1]
+	}
+	return instr.Op + "(" + strings.Join(operands, ", ") + ")"
+}
+
+// Render returns the dependency graph in the given format, "dot" for
+// Graphviz or "mermaid". The critical path is highlighted and pruned
+// instructions are dashed.
+func (plan *Plan) Render(format string) (string, error) {
+	critical := make(map[string]bool)
+	for i := 1; i < len(plan.CriticalPath); i++ {
+		critical[plan.CriticalPath[i-1]+"\x00"+plan.CriticalPath[i]] = true
+	}
+	index := make(map[string]int)
+	for _, n := range plan.Nodes {
+		index[n.Var] = n.Index
+	}
+
+	var b strings.Builder
+	switch format {
+	case "dot":
+		b.WriteString("digraph plan {\n\trankdir=LR;\n")
+		for _, n := range plan.Nodes {
+			style := ""
+			if n.Pruned {
+				style = ", style=dashed"
+			}
+			fmt.Fprintf(&b, "\t%q [label=%q%s];\n", n.Var, n.Var+" = "+n.Expr, style)
+		}
+		for _, n := range plan.Nodes {
+			for _, dep := range n.Deps {
+				style := ""
+				if critical[dep+"\x00"+n.Var] {
+					style = " [color=red]"
+				}
+				fmt.Fprintf(&b, "\t%q -> %q%s;\n", dep, n.Var, style)
+			}
+		}
+		b.WriteString("}\n")
+	case "mermaid":
+		b.WriteString("graph LR\n")
+		for _, n := range plan.Nodes {
+			label := strings.ReplaceAll(n.Var+" = "+n.Expr, `"`, "#quot;")
+			fmt.Fprintf(&b, "\tn%d[\"%s\"]\n", n.Index, label)
+			if n.Pruned {
+				fmt.Fprintf(&b, "\tstyle n%d stroke-dasharray: 5 5\n", n.Index)
+			}
+		}
+		link := 0
+		for _, n := range plan.Nodes {
+			for _, dep := range n.Deps {
+				fmt.Fprintf(&b, "\tn%d --> n%d\n", index[dep], n.Index)
+				if critical[dep+"\x00"+n.Var] {
+					fmt.Fprintf(&b, "\tlinkStyle %d stroke:red\n", link)
+				}
+				link++
+			}
+		}
+	default:
+		return "", fmt.Errorf("unknown plan format %q", format)
+	}
+	return b.String(), nil
+}
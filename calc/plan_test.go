@@ -0,0 +1,68 @@
+package calc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	p, err := NewCalculator().Prepare([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(10), Right: int64(2)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(5)},
+		{Type: "calc", Op: "-", Var: "q", Left: "y", Right: int64(20)},
+		{Type: "calc", Op: "+", Var: "unusedA", Left: "y", Right: int64(100)},
+		{Type: "print", Var: "q"},
+		{Type: "calc", Op: "min", Var: "z", Left: "x", Right: int64(15)},
+		{Type: "print", Var: "z"},
+	})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	plan := p.Plan()
+
+	if expected := [][]string{{"x"}, {"y", "z"}, {"q"}}; !reflect.DeepEqual(plan.Levels, expected) {
+		t.Errorf("expected levels %v, got %v", expected, plan.Levels)
+	}
+	if expected := []string{"x", "y", "q"}; !reflect.DeepEqual(plan.CriticalPath, expected) {
+		t.Errorf("expected critical path %v, got %v", expected, plan.CriticalPath)
+	}
+	if plan.CriticalPathLength != 3*defaultCost || plan.Estimate != 3*defaultCost {
+		t.Errorf("expected critical path length and estimate of 3 operations, got %v and %v", plan.CriticalPathLength, plan.Estimate)
+	}
+	if !reflect.DeepEqual(plan.Pruned, []string{"unusedA"}) {
+		t.Errorf("expected unusedA pruned, got %v", plan.Pruned)
+	}
+	if n := plan.Nodes[4]; n.Var != "z" || n.Expr != "min(x, 15)" || n.Level != 1 || !reflect.DeepEqual(n.Deps, []string{"x"}) {
+		t.Errorf("unexpected node %+v", n)
+	}
+
+	dot, err := plan.Render("dot")
+	if err != nil {
+		t.Fatalf("Render(dot) failed: %v", err)
+	}
+	for _, line := range []string{
+		`"x" [label="x = 10 + 2"];`,
+		`"unusedA" [label="unusedA = y + 100", style=dashed];`,
+		`"x" -> "y" [color=red];`,
+		`"x" -> "z";`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("DOT output lacks %s:\n%s", line, dot)
+		}
+	}
+
+	mermaid, err := plan.Render("mermaid")
+	if err != nil {
+		t.Fatalf("Render(mermaid) failed: %v", err)
+	}
+	for _, line := range []string{`n0["x = 10 + 2"]`, "n0 --> n1", "linkStyle 0 stroke:red", "style n3 stroke-dasharray: 5 5"} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("Mermaid output lacks %s:\n%s", line, mermaid)
+		}
+	}
+
+	if _, err := plan.Render("svg"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,61 @@
+package calc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestPrintModes(t *testing.T) {
+	c := NewCalculator(WithClock(NewVirtualClock(at(0))))
+	instructions := []Instruction{
+		{Type: "print", Var: "x"},
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(2)},
+		{Type: "print", Var: "x"},
+		{Type: "print", Var: "nope"},
+	}
+
+	tests := []struct {
+		mode     PrintMode
+		expected []Result
+	}{
+		{PrintFinal, []Result{
+			{Var: "x", Value: 3},
+			{Var: "x", Value: 3},
+			{Var: "nope", Missing: true, Error: "variable nope not defined"},
+		}},
+		{PrintProgramOrder, []Result{
+			{Var: "x", Missing: true, Error: "variable x not yet defined"},
+			{Var: "x", Value: 3},
+			{Var: "nope", Missing: true, Error: "variable nope not defined"},
+		}},
+	}
+	for _, tt := range tests {
+		report, err := c.Execute(context.Background(), instructions, RunOptions{PrintMode: tt.mode})
+		if err != nil {
+			t.Fatalf("%s: Execute failed: %v", tt.mode, err)
+		}
+		if !reflect.DeepEqual(report.Results, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.mode, tt.expected, report.Results)
+		}
+	}
+
+	// A print ahead of the only definition its variable needs does not keep
+	// the definition from being pruned.
+	report, err := c.Execute(context.Background(), instructions[:2], RunOptions{PrintMode: PrintProgramOrder})
+	if err != nil || report.Pruned != 1 {
+		t.Errorf("expected x to be pruned, got %d pruned, %v", report.Pruned, err)
+	}
+
+	if _, err := c.Execute(context.Background(), instructions, RunOptions{PrintMode: "last"}); err == nil {
+		t.Error("expected error for unknown print mode")
+	}
+
+	report, err = c.Execute(context.Background(), instructions, RunOptions{Versioned: true})
+	if err != nil {
+		t.Fatalf("versioned Execute failed: %v", err)
+	}
+	if res := report.Results[0]; !res.Missing || res.Error != "variable x not yet defined" {
+		t.Errorf("expected versioned print ahead of x to be missing, got %+v", res)
+	}
+}
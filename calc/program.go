@@ -0,0 +1,392 @@
+package calc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Program is a validated batch with a precomputed schedule. Operands of the
+// form "$name" are parameters bound on every Run. A Program is immutable and
+// can be run any number of times, also concurrently.
+type Program struct {
+	calc     *Calculator
+	opts     RunOptions
+	instrs   []Instruction
+	calcOps  []Instruction
+	printOps []Instruction
+	graph    *graph
+	needed   map[string]bool
+	params   []string
+	prints   map[string]int
+	// early marks the prints, by position in printOps, that precede the
+	// definition of their variable in program order.
+	early map[int]bool
+
+	dependents map[string][]string
+	cost       map[string]time.Duration
+	priority   map[string]time.Duration
+}
+
+// Prepare validates instructions and plans their execution once, so they
+// can be run repeatedly with Program.Run.
+func (c *Calculator) Prepare(instructions []Instruction) (*Program, error) {
+	return c.PrepareOptions(instructions, RunOptions{})
+}
+
+// PrepareOptions is like Prepare with the options of Execute.
+func (c *Calculator) PrepareOptions(instructions []Instruction, opts RunOptions) (*Program, error) {
+	p := &Program{
+		calc:   c,
+		opts:   opts,
+		instrs: append([]Instruction(nil), instructions...),
+		prints: make(map[string]int),
+		early:  make(map[int]bool),
+	}
+	switch opts.PrintMode {
+	case "", PrintFinal, PrintProgramOrder:
+	default:
+		return nil, fmt.Errorf("unknown print mode %q", opts.PrintMode)
+	}
+	if opts.Versioned {
+		versioned, err := versionInstructions(p.instrs)
+		if err != nil {
+			return nil, err
+		}
+		p.instrs = versioned
+	}
+
+	seen := make(map[string]bool)
+	var printIndex []int
+	for i, instr := range p.instrs {
+		switch instr.Type {
+		case "print":
+			p.printOps = append(p.printOps, instr)
+			printIndex = append(printIndex, i)
+		case "calc":
+			p.calcOps = append(p.calcOps, instr)
+			for _, operand := range []interface{}{instr.Left, instr.Right} {
+				if isParam(operand) && !seen[operand.(string)[1:]] {
+					seen[operand.(string)[1:]] = true
+					p.params = append(p.params, operand.(string)[1:])
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unknown operation: '%s'", instr.Type)
+		}
+	}
+	sort.Strings(p.params)
+
+	if err := c.registry.validate(p.instrs, opts.BigInt); err != nil {
+		return nil, err
+	}
+
+	if err := checkLiterals(p.instrs, opts.BigInt, c.decimal); err != nil {
+		return nil, err
+	}
+
+	g, err := buildGraph(p.instrs)
+	if err != nil {
+		return nil, err
+	}
+	p.graph = g
+
+	if err := c.checkDecimalOps(p.instrs, g, nil); err != nil {
+		return nil, err
+	}
+
+	// In program order a print only sees the variables defined above it. A
+	// versioned print ahead of every assignment keeps the plain name.
+	var answered []Instruction
+	for i, instr := range p.printOps {
+		def, defined := g.index[instr.Var]
+		early := opts.PrintMode == PrintProgramOrder && defined && def > printIndex[i]
+		if opts.Versioned && !defined {
+			_, early = g.index[versionName(instr.Var, 1)]
+		}
+		if early {
+			p.early[i] = true
+			continue
+		}
+		p.prints[instr.Var]++
+		answered = append(answered, instr)
+	}
+
+	if !opts.FullEvaluation {
+		p.needed = g.required(answered)
+	}
+	p.dependents, p.cost, p.priority = g.criticalPaths(p.instrs, c.registry, p.needed)
+	return p, nil
+}
+
+// Params returns the parameter names of the program, without the leading $,
+// in sorted order.
+func (p *Program) Params() []string {
+	return append([]string{}, p.params...)
+}
+
+// Run executes the program like Execute with params binding the value of
+// every "$name" operand, keyed by name. Values are literals as in
+// instructions: numbers, or strings holding integers and decimals.
+func (p *Program) Run(ctx context.Context, params map[string]interface{}) (*Report, error) {
+	return p.RunStream(ctx, params, nil)
+}
+
+// RunStream is like Run and additionally calls emit with the result of
+// every print as soon as its variable is computed, so in the order the
+// values become available rather than the order of the prints. emit is
+// called from its own goroutine, one result at a time, so a slow consumer
+// does not hold up the scheduler. If emit returns an error, the run is
+// canceled and RunStream returns that error.
+func (p *Program) RunStream(ctx context.Context, params map[string]interface{}, emit func(Result) error) (*Report, error) {
+	e := newExecution(p.calc)
+	e.bigInt = p.opts.BigInt
+	if err := p.bind(e, params); err != nil {
+		return nil, err
+	}
+
+	report := &Report{Statuses: make([]InstructionStatus, 0, len(p.calcOps))}
+	var scheduled []*node
+	for _, instr := range p.calcOps {
+		n := &node{
+			index:    p.graph.index[instr.Var],
+			instr:    instr,
+			priority: p.priority[instr.Var],
+			pending:  len(p.graph.deps[instr.Var]),
+		}
+		e.nodes[instr.Var] = n
+		if p.needed != nil && !p.needed[instr.Var] {
+			n.status = StatusPruned
+			report.Pruned++
+			continue
+		}
+		scheduled = append(scheduled, n)
+	}
+
+	var push func(Result)
+	var emitErr error
+	drain := func() {}
+	if emit != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		// A print is emitted at most once, so the queue never fills up.
+		// The tracker keeps a virtual clock still until a result is
+		// emitted, as if emit took no time.
+		tracker := trackerOf(p.calc.clock)
+		queue := make(chan Result, len(p.printOps))
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for res := range queue {
+				if emitErr == nil {
+					if emitErr = emit(res); emitErr != nil {
+						cancel()
+					}
+				}
+				tracker.End()
+			}
+		}()
+		drain = func() {
+			close(queue)
+			<-done
+		}
+		push = func(res Result) {
+			tracker.Begin()
+			queue <- res
+		}
+	}
+
+	report.Started = p.calc.clock.Now()
+	p.schedule(ctx, e, scheduled, report.Started, push)
+	drain()
+
+	var firstErr error
+	var pending []string
+	for _, instr := range p.calcOps {
+		n := e.nodes[instr.Var]
+		report.Statuses = append(report.Statuses, InstructionStatus{
+			Index:  n.index,
+			Var:    instr.Var,
+			Status: n.status,
+			Err:    n.err,
+		})
+		if n.status == StatusFailed && firstErr == nil {
+			firstErr = n.err
+		}
+		if n.status != StatusOK && n.status != StatusPruned {
+			pending = append(pending, instr.Var)
+		}
+	}
+	if p.opts.Trace {
+		report.Trace = e.trace(p.calcOps, report.Started)
+	}
+	if emitErr != nil {
+		return report, emitErr
+	}
+	if firstErr != nil {
+		return report, firstErr
+	}
+	if err := ctx.Err(); err != nil && len(pending) > 0 {
+		return report, &IncompleteError{Err: err, Pending: pending}
+	}
+
+	for i, printInstr := range p.printOps {
+		if val, ok := e.vars.Load(printInstr.Var); ok && !p.early[i] {
+			report.Results = append(report.Results, p.result(printInstr.Var, val))
+		} else {
+			report.Results = append(report.Results, p.missing(i))
+		}
+	}
+
+	return report, nil
+}
+
+// bind converts the parameter values into operand values of e. Decimal
+// values are checked against the operations they flow into, the only part
+// of validation that depends on parameters.
+func (p *Program) bind(e *execution, params map[string]interface{}) error {
+	for name := range params {
+		if i := sort.SearchStrings(p.params, name); i == len(p.params) || p.params[i] != name {
+			return fmt.Errorf("unknown parameter $%s", name)
+		}
+	}
+
+	e.params = make(map[string]interface{}, len(p.params))
+	var decimal map[string]bool
+	for _, name := range p.params {
+		v, ok := params[name]
+		if !ok {
+			return fmt.Errorf("missing parameter $%s", name)
+		}
+		var val interface{}
+		var err error
+		if s, ok := v.(string); ok && isDecimalLiteral(s) {
+			val, err = p.calc.decimal.Parse(s)
+			if decimal == nil {
+				decimal = make(map[string]bool)
+			}
+			decimal[name] = true
+		} else {
+			val, err = literalValue(v, p.opts.BigInt)
+		}
+		if err != nil {
+			return fmt.Errorf("parameter $%s: %w", name, err)
+		}
+		e.params[name] = val
+	}
+
+	if decimal != nil {
+		return p.calc.checkDecimalOps(p.instrs, p.graph, decimal)
+	}
+	return nil
+}
+
+// missing is the Result of the print at position i of printOps, which has
+// no value.
+func (p *Program) missing(i int) Result {
+	res := Result{Var: p.printOps[i].Var, Missing: true}
+	if p.opts.Versioned {
+		res.Var, res.Version = splitVersion(res.Var)
+	}
+	reason := "not defined"
+	if p.early[i] {
+		reason = "not yet defined"
+	}
+	res.Error = fmt.Sprintf("variable %s %s", res.Var, reason)
+	return res
+}
+
+// ProgramStore keeps prepared programs under generated IDs so that clients
+// can register a program once and run it by reference. Programs that have
+// not been used for the TTL of the store expire. It is safe for concurrent
+// use.
+type ProgramStore struct {
+	calc *Calculator
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	programs map[string]*storedProgram
+}
+
+type storedProgram struct {
+	program *Program
+	expires time.Time
+}
+
+// NewProgramStore creates a store whose programs expire ttl after their last
+// use, measured with the clock of c.
+func NewProgramStore(c *Calculator, ttl time.Duration) *ProgramStore {
+	return &ProgramStore{calc: c, ttl: ttl, programs: make(map[string]*storedProgram)}
+}
+
+func (s *ProgramStore) TTL() time.Duration {
+	return s.ttl
+}
+
+// Add stores p and returns its ID.
+func (s *ProgramStore) Add(p *Program) (string, error) {
+	id, err := newID()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.programs[id] = &storedProgram{program: p, expires: s.calc.clock.Now().Add(s.ttl)}
+	return id, nil
+}
+
+// Get returns the program with the given ID and extends its lifetime.
+func (s *ProgramStore) Get(id string) (*Program, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.programs[id]
+	if !ok {
+		return nil, false
+	}
+	now := s.calc.clock.Now()
+	if !now.Before(stored.expires) {
+		delete(s.programs, id)
+		return nil, false
+	}
+	stored.expires = now.Add(s.ttl)
+	return stored.program, true
+}
+
+// Delete removes the program with the given ID and reports whether it existed.
+func (s *ProgramStore) Delete(id string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.programs[id]
+	delete(s.programs, id)
+	return ok
+}
+
+// Expire removes the programs whose TTL has passed and returns their number.
+func (s *ProgramStore) Expire() int {
+	now := s.calc.clock.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for id, stored := range s.programs {
+		if !now.Before(stored.expires) {
+			delete(s.programs, id)
+			n++
+		}
+	}
+	return n
+}
+
+// newID returns a random ID for a stored program.
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
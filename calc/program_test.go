@@ -0,0 +1,137 @@
+package calc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgramRun(t *testing.T) {
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
+	p, err := calc.Prepare([]Instruction{
+		{Type: "calc", Op: "*", Var: "total", Left: "$price", Right: "$qty"},
+		{Type: "calc", Op: "-", Var: "net", Left: "total", Right: "$discount"},
+		{Type: "print", Var: "net"},
+	})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	if !reflect.DeepEqual(p.Params(), []string{"discount", "price", "qty"}) {
+		t.Errorf("unexpected params %v", p.Params())
+	}
+
+	tests := []struct {
+		params map[string]interface{}
+		value  int64
+		exact  string
+	}{
+		{map[string]interface{}{"price": int64(10), "qty": int64(3), "discount": int64(5)}, 25, ""},
+		{map[string]interface{}{"price": json.Number("7"), "qty": "2", "discount": float64(0)}, 14, ""},
+		{map[string]interface{}{"price": "19.99", "qty": int64(3), "discount": "0.97"}, 59, "59.000000"},
+	}
+
+	var wg sync.WaitGroup
+	for _, tt := range tests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report, err := p.Run(context.Background(), tt.params)
+			if err != nil {
+				t.Errorf("Run(%v) failed: %v", tt.params, err)
+				return
+			}
+			if len(report.Results) != 1 || report.Results[0].Value != tt.value || report.Results[0].Exact != tt.exact {
+				t.Errorf("Run(%v): expected %d %q, got %v", tt.params, tt.value, tt.exact, report.Results)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestProgramParamErrors(t *testing.T) {
+	calc := NewCalculator(WithClock(NewVirtualClock(at(0))))
+	p, err := calc.Prepare([]Instruction{
+		{Type: "calc", Op: "%", Var: "x", Left: "$a", Right: int64(2)},
+		{Type: "print", Var: "x"},
+	})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	if _, err := p.Run(context.Background(), nil); err == nil {
+		t.Error("expected error for missing parameter")
+	}
+	if _, err := p.Run(context.Background(), map[string]interface{}{"a": int64(1), "b": int64(2)}); err == nil {
+		t.Error("expected error for unknown parameter")
+	}
+	if _, err := p.Run(context.Background(), map[string]interface{}{"a": float64(1.5)}); err == nil {
+		t.Error("expected error for non-integer parameter")
+	}
+
+	_, err = p.Run(context.Background(), map[string]interface{}{"a": "1.5"})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Kind != UnsupportedOperation || verr.Reason != "decimal" {
+		t.Errorf("expected unsupported decimal operation, got %v", err)
+	}
+
+	_, err = calc.Calculate([]Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: "$a", Right: int64(1)},
+		{Type: "print", Var: "x"},
+	})
+	if err == nil {
+		t.Error("expected error for unbound parameter in Calculate")
+	}
+}
+
+func TestProgramStore(t *testing.T) {
+	clock := NewVirtualClock(at(0))
+	c := NewCalculator(WithClock(clock))
+	store := NewProgramStore(c, time.Minute)
+	p, err := c.Prepare([]Instruction{{Type: "print", Var: "x"}})
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+
+	add := func() string {
+		t.Helper()
+		id, err := store.Add(p)
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		return id
+	}
+	id := add()
+	if got, ok := store.Get(id); !ok || got != p {
+		t.Errorf("Get(%s) = %v, %v", id, got, ok)
+	}
+	if add() == id {
+		t.Error("expected distinct IDs")
+	}
+	if !store.Delete(id) || store.Delete(id) {
+		t.Error("expected Delete to succeed exactly once")
+	}
+	if _, ok := store.Get(id); ok {
+		t.Error("expected program to be deleted")
+	}
+
+	used, unused := add(), add()
+	clock.Advance(50 * time.Second)
+	if _, ok := store.Get(used); !ok {
+		t.Fatal("program expired before its TTL")
+	}
+	clock.Advance(50 * time.Second)
+	if n := store.Expire(); n != 2 {
+		t.Errorf("expected the unused programs to expire, got %d", n)
+	}
+	if _, ok := store.Get(unused); ok {
+		t.Error("expected unused program to expire")
+	}
+	clock.Advance(time.Minute)
+	if _, ok := store.Get(used); ok {
+		t.Error("expected program to expire a TTL after its last use")
+	}
+}
@@ -0,0 +1,99 @@
+package calc
+
+import "context"
+
+// subscriber queues the changes for one Subscribe call, so a slow consumer
+// never blocks the session. Only the latest state of a variable is kept, so
+// the queue holds at most one entry per variable, in the order they changed.
+type subscriber struct {
+	vars    map[string]bool
+	pending map[string]Variable
+	order   []string
+	wake    chan struct{}
+}
+
+func (sub *subscriber) push(v Variable) {
+	if _, ok := sub.pending[v.Var]; !ok {
+		sub.order = append(sub.order, v.Var)
+	}
+	sub.pending[v.Var] = v
+}
+
+// Subscribe calls fn with the new state of the given variables, or of all
+// variables if none are given, every time one is computed or fails: again
+// whenever it or one of its dependencies is redefined. It starts with the
+// current state of those already computed. fn is called from the calling
+// goroutine, one change at a time; if fn falls behind, it only receives the
+// latest state of each variable that changed meanwhile. Subscribe returns
+// ctx.Err() once ctx is done, or nil once the session is closed and the last
+// changes are delivered.
+func (s *Session) Subscribe(ctx context.Context, vars []string, fn func(Variable)) error {
+	sub := &subscriber{pending: make(map[string]Variable), wake: make(chan struct{}, 1)}
+	if len(vars) > 0 {
+		sub.vars = make(map[string]bool, len(vars))
+		for _, v := range vars {
+			sub.vars[v] = true
+		}
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrSessionClosed
+	}
+	for _, v := range s.vars() {
+		if v.Status != StatusPending && sub.wants(v.Var) {
+			sub.push(v)
+		}
+	}
+	s.subs[sub] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, sub)
+		s.mu.Unlock()
+	}()
+
+	deliver := func() {
+		s.mu.Lock()
+		changes := make([]Variable, len(sub.order))
+		for i, name := range sub.order {
+			changes[i] = sub.pending[name]
+		}
+		clear(sub.pending)
+		sub.order = sub.order[:0]
+		s.mu.Unlock()
+		for _, c := range changes {
+			fn(c)
+		}
+	}
+	for {
+		deliver()
+		select {
+		case <-sub.wake:
+		case <-s.done:
+			deliver()
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (sub *subscriber) wants(v string) bool {
+	return sub.vars == nil || sub.vars[v]
+}
+
+// notify queues the outcome of c for its subscribers.
+func (s *Session) notify(c *cell) {
+	for sub := range s.subs {
+		if !sub.wants(c.name) {
+			continue
+		}
+		sub.push(s.variable(c))
+		select {
+		case sub.wake <- struct{}{}:
+		default:
+		}
+	}
+}
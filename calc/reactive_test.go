@@ -0,0 +1,260 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingRegistry registers an addition under each name that counts its
+// executions.
+func countingRegistry(names ...string) (*OperationRegistry, func(string) int) {
+	r := NewOperationRegistry()
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	for _, name := range names {
+		r.Register(name, 2, func(args []int64) (int64, error) {
+			mu.Lock()
+			calls[name]++
+			mu.Unlock()
+			return args[0] + args[1], nil
+		}, 10*time.Millisecond)
+	}
+	return r, func(name string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls[name]
+	}
+}
+
+func TestReactiveSession(t *testing.T) {
+	r, calls := countingRegistry("a", "b", "c", "d", "e")
+	clock := NewVirtualClock(at(0))
+	s := NewCalculator(WithRegistry(r), WithClock(clock)).NewSession(context.Background(), RunOptions{Reactive: true})
+	ctx := context.Background()
+
+	results, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "a", Var: "a", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "b", Var: "b", Left: "a", Right: int64(1)},
+		{Type: "calc", Op: "c", Var: "c", Left: "a", Right: int64(2)},
+		{Type: "calc", Op: "d", Var: "d", Left: "b", Right: "c"},
+		{Type: "calc", Op: "e", Var: "e", Left: int64(5), Right: int64(5)},
+		{Type: "print", Var: "d"},
+	})
+	if err != nil || !reflect.DeepEqual(results, []Result{{Var: "d", Value: 7}}) {
+		t.Fatalf("initial batch: got %v, %v", results, err)
+	}
+
+	start := clock.Now()
+	results, err = s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "a", Var: "a", Left: int64(10), Right: int64(0)},
+		{Type: "print", Var: "d"},
+		{Type: "print", Var: "e"},
+	})
+	if err != nil || !reflect.DeepEqual(results, []Result{{Var: "d", Value: 23}, {Var: "e", Value: 10}}) {
+		t.Fatalf("redefinition: got %v, %v", results, err)
+	}
+	// b and c are recomputed in parallel, e is not recomputed at all.
+	if d := clock.Now().Sub(start); d != 30*time.Millisecond {
+		t.Errorf("expected recomputation to take 30ms, got %v", d)
+	}
+	for name, expected := range map[string]int{"a": 2, "b": 2, "c": 2, "d": 2, "e": 1} {
+		if got := calls(name); got != expected {
+			t.Errorf("%s: expected %d executions, got %d", name, expected, got)
+		}
+	}
+
+	_, err = s.Submit(Instruction{Type: "calc", Op: "a", Var: "a", Left: "d", Right: int64(1)})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Kind != DependencyCycle {
+		t.Errorf("expected dependency cycle, got %v", err)
+	}
+	s.Close()
+}
+
+func TestReactiveRedefineWhileRunning(t *testing.T) {
+	r, _ := countingRegistry("+")
+	clock := NewVirtualClock(at(0))
+	s := NewCalculator(WithRegistry(r), WithClock(clock)).NewSession(context.Background(), RunOptions{Reactive: true})
+
+	clock.Begin()
+	s.Submit(Instruction{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)})
+	s.Submit(Instruction{Type: "calc", Op: "+", Var: "b", Left: "a", Right: int64(1)})
+	s.Submit(Instruction{Type: "calc", Op: "+", Var: "a", Left: int64(5), Right: int64(5)})
+	f, _ := s.Submit(Instruction{Type: "print", Var: "b"})
+	clock.End()
+
+	if res, err := f.Wait(context.Background()); err != nil || res.Value != 11 {
+		t.Errorf("expected b = 11 from the latest a, got %v, %v", res, err)
+	}
+	s.Close()
+}
+
+func TestReactiveBatchRedefinitions(t *testing.T) {
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(context.Background(), RunOptions{Reactive: true})
+	ctx := context.Background()
+	if _, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(0)},
+		{Type: "calc", Op: "+", Var: "v", Left: "a", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "w", Left: int64(1), Right: int64(0)},
+	}); err != nil {
+		t.Fatalf("initial batch failed: %v", err)
+	}
+
+	// Applied one at a time, a = w + 1 would close the cycle a -> w -> v -> a
+	// that the redefinition of v breaks.
+	results, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "w", Left: "v", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "a", Left: "w", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "v", Left: int64(5), Right: int64(0)},
+		{Type: "print", Var: "a"},
+	})
+	if err != nil || !reflect.DeepEqual(results, []Result{{Var: "a", Value: 7}}) {
+		t.Fatalf("expected [{a 7}], got %v, %v", results, err)
+	}
+	values := make(map[string]int64)
+	for _, v := range s.Vars() {
+		values[v.Var] = v.Value
+	}
+	if !reflect.DeepEqual(values, map[string]int64{"a": 7, "v": 5, "w": 6}) {
+		t.Errorf("unexpected variables %v", values)
+	}
+
+	// Checked against the session before the batch, v = a + 1 would close
+	// the cycle v -> a -> w -> v that the redefinition of a breaks.
+	if _, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "a", Left: int64(2), Right: int64(0)},
+		{Type: "calc", Op: "+", Var: "v", Left: "a", Right: int64(1)},
+	}); err != nil {
+		t.Errorf("expected batch that is acyclic once applied, got %v", err)
+	}
+
+	_, err = s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "v", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "a", Left: "w", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "v", Left: "a", Right: int64(1)},
+	})
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Kind != DuplicateVariable {
+		t.Errorf("expected duplicate variable, got %v", err)
+	}
+	_, err = s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "z", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "a", Left: "w", Right: int64(1)},
+	})
+	if !errors.As(err, &ve) || ve.Kind != DependencyCycle {
+		t.Errorf("expected dependency cycle, got %v", err)
+	}
+	if s.defined("z") {
+		t.Error("a rejected batch must not define variables")
+	}
+	_, err = s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "5", Left: int64(1), Right: int64(1)},
+	})
+	if !errors.As(err, &ve) || ve.Kind != InvalidName || ve.Var != "5" {
+		t.Errorf("expected invalid name, got %v", err)
+	}
+	s.Close()
+}
+
+func TestSessionSubscribe(t *testing.T) {
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(context.Background(), RunOptions{Reactive: true})
+	ctx := context.Background()
+	if _, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(3)},
+		{Type: "calc", Op: "+", Var: "z", Left: int64(1), Right: int64(1)},
+		{Type: "print", Var: "y"},
+	}); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	changes := make(chan Variable, 10)
+	done := make(chan error)
+	go func() {
+		done <- s.Subscribe(ctx, []string{"y"}, func(v Variable) { changes <- v })
+	}()
+	if v := <-changes; v.Var != "y" || v.Value != 6 {
+		t.Errorf("expected current state y = 6, got %+v", v)
+	}
+
+	s.Calculate(ctx, []Instruction{{Type: "calc", Op: "+", Var: "z", Left: int64(2), Right: int64(2)}})
+	s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(2), Right: int64(3)},
+		{Type: "print", Var: "y"},
+	})
+	if v := <-changes; v.Var != "y" || v.Value != 15 || v.Status != StatusOK {
+		t.Errorf("expected change y = 15, got %+v", v)
+	}
+
+	s.Close()
+	if err := <-done; err != nil {
+		t.Errorf("expected Subscribe to end with the session, got %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("unexpected changes %v", <-changes)
+	}
+}
+
+func TestSessionSubscribeSlowConsumer(t *testing.T) {
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(context.Background(), RunOptions{Reactive: true})
+	ctx := context.Background()
+	define := func(x int64) {
+		t.Helper()
+		if _, err := s.Calculate(ctx, []Instruction{
+			{Type: "calc", Op: "+", Var: "x", Left: x, Right: int64(0)},
+			{Type: "print", Var: "x"},
+		}); err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+	}
+	define(1)
+	if _, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(3)},
+		{Type: "print", Var: "y"},
+	}); err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	gate := make(chan struct{})
+	started := make(chan struct{})
+	var got []Variable
+	done := make(chan error)
+	go func() {
+		done <- s.Subscribe(ctx, nil, func(v Variable) {
+			if got = append(got, v); len(got) == 1 {
+				close(started)
+				<-gate
+			}
+		})
+	}()
+	<-started
+	for x := int64(2); x <= 10; x++ {
+		define(x)
+	}
+	s.Calculate(ctx, []Instruction{{Type: "print", Var: "y"}})
+
+	s.mu.Lock()
+	for sub := range s.subs {
+		if !reflect.DeepEqual(sub.order, []string{"x", "y"}) || sub.pending["y"].Value != 30 {
+			t.Errorf("expected only the latest x and y to be queued, got %v", sub.pending)
+		}
+	}
+	s.mu.Unlock()
+
+	close(gate)
+	s.Close()
+	if err := <-done; err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	var values []int64
+	for _, v := range got {
+		values = append(values, v.Value)
+	}
+	if !reflect.DeepEqual(values, []int64{1, 3, 10, 30}) {
+		t.Errorf("expected the initial and the latest x and y, got %v", values)
+	}
+}
@@ -0,0 +1,201 @@
+package calc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const defaultCost = 50 * time.Millisecond
+
+// OpFunc computes an operation over its operands. Binary operations receive
+// left and right, unary ones only left.
+type OpFunc func(args []int64) (int64, error)
+
+type Operation struct {
+	Name    string
+	Arity   int
+	Latency LatencyModel
+	Func    OpFunc
+	// Big and Decimal are optional; operations without them are rejected in
+	// big integer mode and on decimal operands respectively.
+	Big     BigFunc
+	Decimal DecimalFunc
+}
+
+// OperationRegistry is the set of operations a Calculator accepts in calc
+// instructions. It is safe for concurrent use, so operations can be
+// registered while a Calculator using the registry serves requests.
+type OperationRegistry struct {
+	mu  sync.RWMutex
+	ops map[string]Operation
+}
+
+func NewOperationRegistry() *OperationRegistry {
+	return &OperationRegistry{ops: make(map[string]Operation)}
+}
+
+// DefaultRegistry returns a new registry holding the built-in operations.
+func DefaultRegistry() *OperationRegistry {
+	r := NewOperationRegistry()
+	r.mustRegister("+", 2, add, defaultCost)
+	r.mustRegister("-", 2, sub, defaultCost)
+	r.mustRegister("*", 2, mul, defaultCost)
+	r.mustRegister("/", 2, div, defaultCost)
+	r.mustRegister("%", 2, mod, defaultCost)
+	r.mustRegister("**", 2, pow, defaultCost)
+	r.mustRegister("min", 2, minOp, defaultCost)
+	r.mustRegister("max", 2, maxOp, defaultCost)
+
+	for name, fn := range map[string]BigFunc{
+		"+": bigAdd, "-": bigSub, "*": bigMul, "/": bigDiv,
+		"%": bigMod, "**": bigPow, "min": bigMin, "max": bigMax,
+	} {
+		if err := r.RegisterBig(name, fn); err != nil {
+			panic(err)
+		}
+	}
+
+	for name, fn := range map[string]DecimalFunc{
+		"+": decAdd, "-": decSub, "*": decMul, "/": decQuo,
+		"min": decMin, "max": decMax,
+	} {
+		if err := r.RegisterDecimal(name, fn); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *OperationRegistry) Register(name string, arity int, fn OpFunc, cost time.Duration) error {
+	if name == "" {
+		return fmt.Errorf("operation name is empty")
+	}
+	if arity != 1 && arity != 2 {
+		return fmt.Errorf("operation %s: unsupported arity %d", name, arity)
+	}
+	if fn == nil {
+		return fmt.Errorf("operation %s: nil function", name)
+	}
+	if cost < 0 {
+		return fmt.Errorf("operation %s: negative cost", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.ops[name]; exists {
+		return fmt.Errorf("operation %s already registered", name)
+	}
+	r.ops[name] = Operation{Name: name, Arity: arity, Latency: ConstantLatency(cost), Func: fn}
+	return nil
+}
+
+// RegisterBig adds the arbitrary-precision implementation of an already
+// registered operation.
+func (r *OperationRegistry) RegisterBig(name string, fn BigFunc) error {
+	if fn == nil {
+		return fmt.Errorf("operation %s: nil function", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, exists := r.ops[name]
+	if !exists {
+		return fmt.Errorf("operation %s is not registered", name)
+	}
+	op.Big = fn
+	r.ops[name] = op
+	return nil
+}
+
+// RegisterDecimal adds the decimal implementation of an already registered
+// operation.
+func (r *OperationRegistry) RegisterDecimal(name string, fn DecimalFunc) error {
+	if fn == nil {
+		return fmt.Errorf("operation %s: nil function", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, exists := r.ops[name]
+	if !exists {
+		return fmt.Errorf("operation %s is not registered", name)
+	}
+	op.Decimal = fn
+	r.ops[name] = op
+	return nil
+}
+
+// SetLatency replaces the latency model of a registered operation, which
+// is a constant cost given to Register by default.
+func (r *OperationRegistry) SetLatency(name string, model LatencyModel) error {
+	if model == nil {
+		return fmt.Errorf("operation %s: nil latency model", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	op, exists := r.ops[name]
+	if !exists {
+		return fmt.Errorf("operation %s is not registered", name)
+	}
+	op.Latency = model
+	r.ops[name] = op
+	return nil
+}
+
+func (r *OperationRegistry) mustRegister(name string, arity int, fn OpFunc, cost time.Duration) {
+	if err := r.Register(name, arity, fn, cost); err != nil {
+		panic(err)
+	}
+}
+
+func (r *OperationRegistry) Lookup(name string) (Operation, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	op, ok := r.ops[name]
+	return op, ok
+}
+
+// Operations returns the registered operations sorted by name.
+func (r *OperationRegistry) Operations() []Operation {
+	r.mu.RLock()
+	ops := make([]Operation, 0, len(r.ops))
+	for _, op := range r.ops {
+		ops = append(ops, op)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Name < ops[j].Name })
+	return ops
+}
+
+// validate checks that every calc instruction uses a registered operation
+// with the right number of operands, available in big integer mode if
+// bigInt is set.
+func (r *OperationRegistry) validate(instructions []Instruction, bigInt bool) error {
+	for i, instr := range instructions {
+		if instr.Type != "calc" {
+			continue
+		}
+		op, ok := r.Lookup(instr.Op)
+		if !ok {
+			return &ValidationError{Kind: UnknownOperation, Index: i, Var: instr.Var, Op: instr.Op}
+		}
+		if !hasArity(instr, op.Arity) {
+			return &ValidationError{Kind: ArityMismatch, Index: i, Var: instr.Var, Op: instr.Op, Arity: op.Arity}
+		}
+		if bigInt && op.Big == nil {
+			return &ValidationError{Kind: UnsupportedOperation, Index: i, Var: instr.Var, Op: instr.Op, Reason: "big integer"}
+		}
+	}
+	return nil
+}
+
+func hasArity(instr Instruction, arity int) bool {
+	if instr.Left == nil {
+		return false
+	}
+	return (instr.Right != nil) == (arity == 2)
+}
@@ -0,0 +1,75 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegisterCustomOperation(t *testing.T) {
+	registry := DefaultRegistry()
+	err := registry.Register("neg", 1, func(args []int64) (int64, error) { return -args[0], nil }, 0)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register("+", 2, func(args []int64) (int64, error) { return 0, nil }, 0); err == nil {
+		t.Error("expected error when registering a duplicate operation")
+	}
+	if err := registry.Register("bad", 3, func(args []int64) (int64, error) { return 0, nil }, 0); err == nil {
+		t.Error("expected error for unsupported arity")
+	}
+
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(10), Right: int64(2)},
+		{Type: "calc", Op: "neg", Var: "y", Left: "x"},
+		{Type: "print", Var: "y"},
+	}
+
+	results, err := NewCalculator(WithRegistry(registry), WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Value != -12 {
+		t.Errorf("expected y = -12, got %v", results)
+	}
+}
+
+func TestOperationValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		instr Instruction
+		kind  ValidationKind
+	}{
+		{"unknown operation", Instruction{Type: "calc", Op: "^", Var: "x", Left: int64(1), Right: int64(2)}, UnknownOperation},
+		{"missing operand", Instruction{Type: "calc", Op: "+", Var: "x", Left: int64(1)}, ArityMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate([]Instruction{tt.instr})
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if verr.Kind != tt.kind {
+				t.Errorf("expected kind %q, got %q", tt.kind, verr.Kind)
+			}
+		})
+	}
+}
+
+func TestOperationsSorted(t *testing.T) {
+	ops := DefaultRegistry().Operations()
+	names := make([]string, len(ops))
+	for i, op := range ops {
+		names[i] = op.Name
+	}
+	expected := []string{"%", "*", "**", "+", "-", "/", "max", "min"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, names)
+		}
+	}
+}
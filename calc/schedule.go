@@ -0,0 +1,266 @@
+package calc
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"time"
+)
+
+// criticalPaths returns for every calc variable in scope (all of them if
+// scope is nil) the variables in scope that depend on it, the estimated
+// latency of its operation and its priority: the total estimate of the
+// longest chain of operations from it to the end of the batch. Running the
+// node with the longest remaining chain first keeps the overall latency
+// minimal when workers are limited.
+func (g *graph) criticalPaths(instructions []Instruction, r *OperationRegistry, scope map[string]bool) (dependents map[string][]string, cost, priority map[string]time.Duration) {
+	inScope := func(v string) bool { return scope == nil || scope[v] }
+
+	dependents = make(map[string][]string)
+	cost = make(map[string]time.Duration)
+	for _, v := range g.order {
+		if !inScope(v) {
+			continue
+		}
+		for _, dep := range g.deps[v] {
+			dependents[dep] = append(dependents[dep], v)
+		}
+		op, _ := r.Lookup(instructions[g.index[v]].Op)
+		cost[v] = op.Latency.Estimate(op.Arity)
+	}
+
+	priority = make(map[string]time.Duration)
+	var visit func(v string) time.Duration
+	visit = func(v string) time.Duration {
+		if p, ok := priority[v]; ok {
+			return p
+		}
+		var longest time.Duration
+		for _, d := range dependents[v] {
+			longest = max(longest, visit(d))
+		}
+		priority[v] = cost[v] + longest
+		return priority[v]
+	}
+	for _, v := range g.order {
+		if inScope(v) {
+			visit(v)
+		}
+	}
+	return dependents, cost, priority
+}
+
+// Estimate predicts how long a Run takes from the latency estimates of the
+// operations, following the same dispatch order and worker limit as the
+// scheduler.
+func (p *Program) Estimate() time.Duration {
+	limit := p.calc.workers
+	pending := make(map[string]int)
+	var ready readyQueue
+	for _, instr := range p.calcOps {
+		v := instr.Var
+		if p.needed != nil && !p.needed[v] {
+			continue
+		}
+		pending[v] = len(p.graph.deps[v])
+		if pending[v] == 0 {
+			ready = append(ready, &node{index: p.graph.index[v], instr: instr, priority: p.priority[v]})
+		}
+	}
+	heap.Init(&ready)
+	if limit == 0 {
+		limit = len(pending)
+	}
+
+	var now time.Duration
+	var running finishQueue
+	for ready.Len() > 0 || running.Len() > 0 {
+		for running.Len() < limit && ready.Len() > 0 {
+			n := heap.Pop(&ready).(*node)
+			heap.Push(&running, finish{at: now + p.cost[n.instr.Var], v: n.instr.Var})
+		}
+		f := heap.Pop(&running).(finish)
+		now = f.at
+		for _, d := range p.dependents[f.v] {
+			if pending[d]--; pending[d] == 0 {
+				instr := p.instrs[p.graph.index[d]]
+				heap.Push(&ready, &node{index: p.graph.index[d], instr: instr, priority: p.priority[d]})
+			}
+		}
+	}
+	return now
+}
+
+type finish struct {
+	at time.Duration
+	v  string
+}
+
+// finishQueue orders simulated running operations by completion time.
+type finishQueue []finish
+
+func (q finishQueue) Len() int           { return len(q) }
+func (q finishQueue) Less(i, j int) bool { return q[i].at < q[j].at }
+func (q finishQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+func (q *finishQueue) Push(x interface{}) { *q = append(*q, x.(finish)) }
+
+func (q *finishQueue) Pop() interface{} {
+	old := *q
+	f := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return f
+}
+
+// readyQueue orders the nodes whose dependencies have finished by priority,
+// then by instruction order.
+type readyQueue []*node
+
+func (q readyQueue) Len() int { return len(q) }
+
+func (q readyQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].index < q[j].index
+}
+
+func (q readyQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *readyQueue) Push(x interface{}) { *q = append(*q, x.(*node)) }
+
+func (q *readyQueue) Pop() interface{} {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return n
+}
+
+// schedule computes the given nodes in dependency order. Ready nodes are
+// dispatched by priority to at most c.workers goroutines at a time, so the
+// number of goroutines never exceeds the available parallelism even for
+// large batches. A node whose dependency did not succeed is not dispatched
+// but marked skipped, or canceled if the dependency was canceled. Once ctx
+// is done no more nodes are dispatched and those left over are canceled.
+// The times at which nodes become ready, start and end are recorded for
+// tracing, and the prints of a computed node are passed to emit if set.
+func (p *Program) schedule(ctx context.Context, e *execution, nodes []*node, started time.Time, emit func(Result)) {
+	limit := p.calc.workers
+	if limit == 0 {
+		limit = len(nodes)
+	}
+
+	clock := p.calc.clock
+	var ready readyQueue
+	for _, n := range nodes {
+		if n.pending == 0 {
+			n.ready = started
+			ready = append(ready, n)
+		}
+	}
+	heap.Init(&ready)
+
+	finished := make(chan *node, len(nodes))
+	release := func(n *node) {
+		stack := []*node{n}
+		for len(stack) > 0 {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, v := range p.dependents[n.instr.Var] {
+				d := e.nodes[v]
+				if d.pending--; d.pending > 0 {
+					continue
+				}
+				if e.blocked(d) {
+					stack = append(stack, d)
+				} else {
+					d.ready = clock.Now()
+					heap.Push(&ready, d)
+				}
+			}
+		}
+	}
+
+	// The tracker counts a node as in flight until its result has been
+	// handled and its ready dependents dispatched, so a virtual clock does
+	// not move while the scheduler still has work to start.
+	tracker := trackerOf(p.calc.clock)
+	running := 0
+	var idle []int
+	dispatch := func() {
+		for running < limit && ready.Len() > 0 && ctx.Err() == nil {
+			n := heap.Pop(&ready).(*node)
+			if len(idle) > 0 {
+				n.worker = idle[len(idle)-1]
+				idle = idle[:len(idle)-1]
+			} else {
+				n.worker = running
+			}
+			running++
+			tracker.Begin()
+			n.start = clock.Now()
+			go func() {
+				e.run(ctx, n)
+				n.end = clock.Now()
+				finished <- n
+			}()
+		}
+	}
+
+	dispatch()
+	for running > 0 {
+		n := <-finished
+		running--
+		idle = append(idle, n.worker)
+		if emit != nil && n.status == StatusOK {
+			val, _ := e.vars.Load(n.instr.Var)
+			for i := 0; i < p.prints[n.instr.Var]; i++ {
+				emit(p.result(n.instr.Var, val))
+			}
+		}
+		release(n)
+		dispatch()
+		tracker.End()
+	}
+
+	for _, n := range nodes {
+		if n.status == "" {
+			n.status = StatusCanceled
+			n.err = ctx.Err()
+		}
+	}
+}
+
+// blocked marks n as skipped or canceled and reports true if one of its
+// dependencies did not succeed.
+func (e *execution) blocked(n *node) bool {
+	for _, dep := range getDependencies(n.instr) {
+		d := e.nodes[dep]
+		if d.status == StatusCanceled {
+			n.status = StatusCanceled
+			n.err = d.err
+			return true
+		}
+		if d.status != StatusOK {
+			n.status = StatusSkipped
+			n.err = fmt.Errorf("variable %s skipped: dependency %s %s", n.instr.Var, dep, d.status)
+			return true
+		}
+	}
+	return false
+}
+
+func (e *execution) run(ctx context.Context, n *node) {
+	n.status, n.err = outcome(ctx, e.processCalc(ctx, n.index, n.instr))
+}
+
+// outcome returns the status of an instruction that returned err.
+func outcome(ctx context.Context, err error) (Status, error) {
+	if err == nil {
+		return StatusOK, nil
+	}
+	if ctxErr := ctx.Err(); ctxErr != nil && err == ctxErr {
+		return StatusCanceled, err
+	}
+	return StatusFailed, err
+}
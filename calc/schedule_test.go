@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSchedulerCriticalPathFirst(t *testing.T) {
+	var mu sync.Mutex
+	var order []int64
+	r := NewOperationRegistry()
+	r.Register("tag", 2, func(args []int64) (int64, error) {
+		mu.Lock()
+		order = append(order, args[1])
+		mu.Unlock()
+		return args[1], nil
+	}, time.Millisecond)
+
+	calc := NewCalculator(WithRegistry(r), WithWorkers(1), WithClock(NewVirtualClock(at(0))))
+	_, err := calc.Calculate([]Instruction{
+		{Type: "calc", Op: "tag", Var: "s1", Left: int64(0), Right: int64(10)},
+		{Type: "calc", Op: "tag", Var: "s2", Left: int64(0), Right: int64(11)},
+		{Type: "calc", Op: "tag", Var: "c3", Left: "c2", Right: int64(3)},
+		{Type: "calc", Op: "tag", Var: "c2", Left: "c1", Right: int64(2)},
+		{Type: "calc", Op: "tag", Var: "c1", Left: int64(0), Right: int64(1)},
+		{Type: "print", Var: "s1"},
+		{Type: "print", Var: "s2"},
+		{Type: "print", Var: "c3"},
+	})
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+
+	// c1 and c2 head longer chains than s1 and s2; c3 ties with them and
+	// comes last in instruction order.
+	if expected := []int64{1, 2, 10, 11, 3}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected execution order %v, got %v", expected, order)
+	}
+}
+
+func TestSchedulerWorkerLimit(t *testing.T) {
+	var instructions []Instruction
+	for i := 0; i < 30; i++ {
+		v := fmt.Sprintf("v%d", i)
+		instructions = append(instructions,
+			Instruction{Type: "calc", Op: "+", Var: v, Left: int64(i), Right: int64(1)},
+			Instruction{Type: "print", Var: v},
+		)
+	}
+
+	clock := NewVirtualClock(at(0))
+	report, err := NewCalculator(WithWorkers(3), WithClock(clock)).Execute(context.Background(), instructions, RunOptions{Trace: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(report.Results) != 30 {
+		t.Errorf("expected 30 results, got %d", len(report.Results))
+	}
+	for _, span := range report.Trace {
+		running := 0
+		for _, other := range report.Trace {
+			if other.Start.Before(span.End) && span.Start.Before(other.End) {
+				running++
+			}
+		}
+		if running > 3 {
+			t.Errorf("expected at most 3 concurrent operations, got %d at %v", running, span.Start.Sub(at(0)))
+		}
+	}
+	if d := clock.Now().Sub(at(0)); d != 10*defaultCost {
+		t.Errorf("expected 30 operations on 3 workers to take %v, got %v", 10*defaultCost, d)
+	}
+}
+
+func TestSchedulerDefaultWorkers(t *testing.T) {
+	n := DefaultWorkers() + 1
+	var instructions []Instruction
+	for i := 0; i < n; i++ {
+		v := fmt.Sprintf("v%d", i)
+		instructions = append(instructions,
+			Instruction{Type: "calc", Op: "+", Var: v, Left: int64(i), Right: int64(1)},
+			Instruction{Type: "print", Var: v},
+		)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		opts     []Option
+		expected time.Duration
+	}{
+		{"default", nil, 2 * defaultCost},
+		{"no limit", []Option{WithWorkers(0)}, defaultCost},
+	} {
+		clock := NewVirtualClock(at(0))
+		if _, err := NewCalculator(append(tt.opts, WithClock(clock))...).Calculate(instructions); err != nil {
+			t.Fatalf("Calculate failed: %v", err)
+		}
+		if d := clock.Now().Sub(at(0)); d != tt.expected {
+			t.Errorf("%s: expected %d operations to take %v, got %v", tt.name, n, tt.expected, d)
+		}
+	}
+}
@@ -0,0 +1,681 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrSessionClosed is returned by Session.Submit after Close.
+var ErrSessionClosed = errors.New("session closed")
+
+// Session evaluates instructions submitted one at a time. Every calc is
+// computed as soon as the variables it references have been computed, which
+// may be submitted later than the calc itself, and every print is answered
+// once its variable is available. The variables stay defined for the
+// lifetime of the session. They are write-once unless the session is
+// reactive: then a calc may redefine a variable, and every variable that
+// depends on it is recomputed.
+type Session struct {
+	e        *execution
+	ctx      context.Context
+	tracker  workTracker
+	reactive bool
+
+	mu      sync.Mutex
+	cells   map[string]*cell
+	count   int
+	closed  bool
+	running int
+	queue   []queued
+	subs    map[*subscriber]bool
+	wg      sync.WaitGroup
+	done    chan struct{}
+}
+
+// cell is a variable of a session, created when it is first defined or
+// referenced. dependents are the defined variables that reference it and
+// pending counts the dependencies that have not finished yet. gen changes
+// whenever the variable has to be recomputed, so that the result of an
+// outdated computation can be discarded.
+type cell struct {
+	name       string
+	index      int
+	instr      Instruction
+	defined    bool
+	gen        int
+	pending    int
+	dependents []*cell
+	status     Status
+	err        error
+	waiters    []*Future
+}
+
+type queued struct {
+	c   *cell
+	gen int
+}
+
+// NewSession starts a session whose operations run until ctx is done. Of
+// opts only BigInt and Reactive apply: a session has no fixed set of prints
+// to prune by, so every calc is executed.
+func (c *Calculator) NewSession(ctx context.Context, opts RunOptions) *Session {
+	e := newExecution(c)
+	e.bigInt = opts.BigInt
+	return &Session{
+		e:        e,
+		ctx:      ctx,
+		tracker:  trackerOf(c.clock),
+		reactive: opts.Reactive,
+		cells:    make(map[string]*cell),
+		subs:     make(map[*subscriber]bool),
+		done:     make(chan struct{}),
+	}
+}
+
+// Future is the pending result of a print.
+type Future struct {
+	done chan struct{}
+	res  Result
+	err  error
+}
+
+// Done is closed once the variable has been computed or can no longer be.
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Result returns the printed value or the reason it could not be computed.
+// It must only be called once Done is closed.
+func (f *Future) Result() (Result, error) {
+	return f.res, f.err
+}
+
+// Wait blocks until the result is available or ctx is done.
+func (f *Future) Wait(ctx context.Context) (Result, error) {
+	select {
+	case <-f.done:
+		return f.Result()
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	}
+}
+
+// Submit adds an instruction to the session. A print returns the Future of
+// its result, a calc returns a nil Future. Instructions are numbered in
+// submission order, rejected ones included, and an invalid calc is rejected
+// with a *ValidationError without affecting the session.
+//
+// A print answers with the value of its variable once no computation of it
+// is outstanding, so in a reactive session a print submitted after a
+// redefinition reports the recomputed value.
+func (s *Session) Submit(instr Instruction) (*Future, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	index := s.count
+	s.count++
+	if s.closed {
+		return nil, ErrSessionClosed
+	}
+	return s.submit(index, instr)
+}
+
+func (s *Session) submit(index int, instr Instruction) (*Future, error) {
+	switch instr.Type {
+	case "print":
+		return s.print(instr.Var), nil
+	case "calc":
+	default:
+		return nil, fmt.Errorf("unknown operation: '%s'", instr.Type)
+	}
+	if err := s.check(index, instr); err != nil {
+		return nil, err
+	}
+	if err := s.checkCycle(index, instr); err != nil {
+		return nil, err
+	}
+	s.define(index, instr)
+	return nil, nil
+}
+
+// print returns the Future of the value of the variable name.
+func (s *Session) print(name string) *Future {
+	f := &Future{done: make(chan struct{})}
+	if c := s.cell(name); c.status == "" {
+		c.waiters = append(c.waiters, f)
+	} else {
+		s.resolve(f, c)
+	}
+	return f
+}
+
+// define (re)defines the variable of a calc that has been validated and
+// computes it.
+func (s *Session) define(index int, instr Instruction) {
+	c := s.cell(instr.Var)
+	if c.defined {
+		for _, dep := range getDependencies(c.instr) {
+			d := s.cells[dep]
+			for i, user := range d.dependents {
+				if user == c {
+					d.dependents = append(d.dependents[:i], d.dependents[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	c.index, c.instr, c.defined = index, instr, true
+	for _, dep := range getDependencies(instr) {
+		d := s.cell(dep)
+		d.dependents = append(d.dependents, c)
+	}
+	s.invalidate(c)
+}
+
+// invalidate (re)computes c and everything that depends on it. The affected
+// variables start as soon as their dependencies are computed, so independent
+// branches are recomputed in parallel and the rest of the session is left
+// alone.
+func (s *Session) invalidate(c *cell) {
+	var affected []*cell
+	seen := make(map[*cell]bool)
+	var visit func(c *cell)
+	visit = func(c *cell) {
+		if seen[c] {
+			return
+		}
+		seen[c] = true
+		affected = append(affected, c)
+		for _, d := range c.dependents {
+			visit(d)
+		}
+	}
+	visit(c)
+
+	for _, a := range affected {
+		a.gen++
+		a.status, a.err = "", nil
+	}
+	for _, a := range affected {
+		if a.status != "" {
+			continue
+		}
+		a.pending = 0
+		var failed *cell
+		for _, dep := range getDependencies(a.instr) {
+			d := s.cells[dep]
+			if d.status == "" {
+				a.pending++
+			} else if d.status != StatusOK && failed == nil {
+				failed = d
+			}
+		}
+		if failed != nil {
+			s.block(a, failed)
+		} else if a.pending == 0 {
+			s.start(a)
+		}
+	}
+}
+
+// Calculate runs a batch in the session and returns the results of its
+// prints in order, like Calculator.Calculate with the variables of the
+// session in scope. The batch is validated as a whole first, so a rejected
+// batch defines nothing; its calcs may only reference variables of the
+// session or of the batch, and prints of variables defined in neither yield
+// a missing result. Indexes in errors of the batch and of its calcs are
+// positions in instructions. If ctx is done first, the calcs keep running in
+// the session.
+func (s *Session) Calculate(ctx context.Context, instructions []Instruction) ([]Result, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrSessionClosed
+	}
+	batch, err := s.validate(instructions)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	start := s.count
+	var futures []*Future
+	for _, instr := range instructions {
+		index := s.count
+		s.count++
+		if _, ok := batch[instr.Var]; instr.Type == "print" && !ok && !s.defined(instr.Var) {
+			f := &Future{done: make(chan struct{})}
+			f.res = Result{Var: instr.Var, Missing: true, Error: fmt.Sprintf("variable %s not defined", instr.Var)}
+			close(f.done)
+			futures = append(futures, f)
+			continue
+		}
+		if instr.Type == "print" {
+			futures = append(futures, s.print(instr.Var))
+		} else {
+			s.define(index, instr)
+		}
+	}
+	s.mu.Unlock()
+
+	results := make([]Result, 0, len(futures))
+	for _, f := range futures {
+		res, err := f.Wait(ctx)
+		if err != nil {
+			return nil, batchError(err, start, len(instructions))
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// batchError renumbers the error of a calc submitted at index start+i of the
+// session as the error of instruction i of its batch of n instructions.
+func batchError(err error, start, n int) error {
+	switch e := err.(type) {
+	case *OperationError:
+		if e.Index >= start && e.Index < start+n {
+			renumbered := *e
+			renumbered.Index -= start
+			return &renumbered
+		}
+	case *ValidationError:
+		if e.Index >= start && e.Index < start+n {
+			renumbered := *e
+			renumbered.Index -= start
+			return &renumbered
+		}
+	}
+	return err
+}
+
+// validate checks a batch before any of it is submitted and returns the
+// position in the batch of every variable it defines.
+func (s *Session) validate(instructions []Instruction) (map[string]int, error) {
+	batch := make(map[string]int)
+	var order []string
+	for index, instr := range instructions {
+		switch instr.Type {
+		case "print":
+			continue
+		case "calc":
+		default:
+			return nil, fmt.Errorf("unknown operation: '%s'", instr.Type)
+		}
+		if err := s.check(index, instr); err != nil {
+			return nil, err
+		}
+		if _, ok := batch[instr.Var]; ok {
+			return nil, &ValidationError{Kind: DuplicateVariable, Index: index, Var: instr.Var}
+		}
+		batch[instr.Var] = index
+		order = append(order, instr.Var)
+	}
+
+	// The batch may close a cycle through variables of the session, and its
+	// redefinitions may break one, so the cycle check runs on the graph the
+	// session has once the whole batch is applied.
+	g := &graph{order: order, deps: make(map[string][]string)}
+	for name, c := range s.cells {
+		if _, ok := batch[name]; c.defined && !ok {
+			g.deps[name] = getDependencies(c.instr)
+		}
+	}
+	for _, v := range order {
+		g.deps[v] = getDependencies(instructions[batch[v]])
+		for _, dep := range g.deps[v] {
+			if _, ok := batch[dep]; !ok && !s.defined(dep) {
+				return nil, &ValidationError{Kind: UndefinedReference, Index: batch[v], Var: v, Ref: dep}
+			}
+		}
+	}
+	if cycle := g.findCycle(); cycle != nil {
+		for _, v := range cycle {
+			if index, ok := batch[v]; ok {
+				return nil, &ValidationError{Kind: DependencyCycle, Index: index, Var: v, Cycle: cycle}
+			}
+		}
+	}
+	return batch, nil
+}
+
+func (s *Session) defined(name string) bool {
+	c, ok := s.cells[name]
+	return ok && c.defined
+}
+
+// Variable is a variable defined in a session. Result is only set once the
+// variable is computed, that is its Status is StatusOK.
+type Variable struct {
+	Result
+	Index  int
+	Status Status
+	Err    error
+}
+
+// Vars returns the variables defined in the session in definition order.
+func (s *Session) Vars() []Variable {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.vars()
+}
+
+func (s *Session) vars() []Variable {
+	var vars []Variable
+	for _, c := range s.cells {
+		if c.defined {
+			vars = append(vars, s.variable(c))
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Index < vars[j].Index })
+	return vars
+}
+
+func (s *Session) variable(c *cell) Variable {
+	v := Variable{Result: Result{Var: c.name}, Index: c.index, Status: c.status, Err: c.err}
+	if c.status == "" {
+		v.Status = StatusPending
+	} else if c.status == StatusOK {
+		val, _ := s.e.vars.Load(c.name)
+		v.Result = newResult(c.name, val)
+	}
+	return v
+}
+
+func (s *Session) cell(name string) *cell {
+	c, ok := s.cells[name]
+	if !ok {
+		c = &cell{name: name}
+		s.cells[name] = c
+	}
+	return c
+}
+
+// check validates a calc against the session: its operation and literals
+// like Prepare does, and its variable against those already defined. Cycles
+// are checked separately, see checkCycle and validate.
+func (s *Session) check(index int, instr Instruction) error {
+	single := []Instruction{instr}
+	err := s.e.calc.registry.validate(single, s.e.bigInt)
+	if err == nil {
+		err = checkLiterals(single, s.e.bigInt, s.e.calc.decimal)
+	}
+	if ve, ok := err.(*ValidationError); ok {
+		ve.Index = index
+		return ve
+	}
+
+	if err := checkName(index, instr.Var); err != nil {
+		return err
+	}
+	for _, operand := range []interface{}{instr.Left, instr.Right} {
+		if isParam(operand) {
+			return fmt.Errorf("instruction %d: parameter %s not allowed in a session", index, operand)
+		}
+	}
+	if s.defined(instr.Var) && !s.reactive {
+		return &ValidationError{Kind: DuplicateVariable, Index: index, Var: instr.Var}
+	}
+
+	for _, dep := range getDependencies(instr) {
+		if dep == instr.Var {
+			return &ValidationError{Kind: SelfReference, Index: index, Var: instr.Var, Ref: dep}
+		}
+	}
+	return nil
+}
+
+// checkCycle rejects a calc that would close a dependency cycle through the
+// variables defined in the session.
+func (s *Session) checkCycle(index int, instr Instruction) error {
+	deps := getDependencies(instr)
+	var path []string
+	seen := make(map[string]bool)
+	var reaches func(v string) bool
+	reaches = func(v string) bool {
+		path = append(path, v)
+		if v == instr.Var {
+			return true
+		}
+		if seen[v] {
+			path = path[:len(path)-1]
+			return false
+		}
+		seen[v] = true
+		if c, ok := s.cells[v]; ok && c.defined {
+			for _, dep := range getDependencies(c.instr) {
+				if reaches(dep) {
+					return true
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	for _, dep := range deps {
+		if reaches(dep) {
+			cycle := append([]string{instr.Var}, path...)
+			return &ValidationError{Kind: DependencyCycle, Index: index, Var: instr.Var, Cycle: cycle}
+		}
+	}
+	return nil
+}
+
+// start runs c once a worker is free, or cancels it if the session is done.
+func (s *Session) start(c *cell) {
+	if err := s.ctx.Err(); err != nil {
+		c.status, c.err = StatusCanceled, err
+		s.finish(c)
+		return
+	}
+	limit := s.e.calc.workers
+	if limit > 0 && s.running >= limit {
+		s.queue = append(s.queue, queued{c, c.gen})
+		return
+	}
+	s.running++
+	s.tracker.Begin()
+	s.wg.Add(1)
+	gen, index, instr := c.gen, c.index, c.instr
+	go func() {
+		defer s.wg.Done()
+		val, err := s.e.compute(s.ctx, index, instr)
+
+		s.mu.Lock()
+		s.running--
+		if c.gen == gen {
+			if err == nil {
+				s.e.vars.Store(c.name, val)
+			}
+			c.status, c.err = outcome(s.ctx, err)
+			s.finish(c)
+		}
+		for len(s.queue) > 0 && (limit == 0 || s.running < limit) {
+			q := s.queue[0]
+			s.queue = s.queue[1:]
+			if q.c.gen == q.gen && q.c.status == "" {
+				s.start(q.c)
+			}
+		}
+		s.mu.Unlock()
+		s.tracker.End()
+	}()
+}
+
+// finish answers the prints of c, notifies its subscribers and starts or
+// blocks its dependents.
+func (s *Session) finish(c *cell) {
+	for _, f := range c.waiters {
+		s.resolve(f, c)
+	}
+	c.waiters = nil
+	s.notify(c)
+	for _, d := range c.dependents {
+		if d.status != "" {
+			continue
+		}
+		if c.status != StatusOK {
+			s.block(d, c)
+		} else if d.pending--; d.pending == 0 {
+			s.start(d)
+		}
+	}
+}
+
+func (s *Session) resolve(f *Future, c *cell) {
+	if c.status == StatusOK {
+		val, _ := s.e.vars.Load(c.name)
+		f.res = newResult(c.name, val)
+	} else {
+		f.err = c.err
+	}
+	close(f.done)
+}
+
+// block marks c as skipped, or canceled, because its dependency dep did not
+// succeed.
+func (s *Session) block(c, dep *cell) {
+	if dep.status == StatusCanceled {
+		c.status, c.err = StatusCanceled, dep.err
+	} else {
+		c.status = StatusSkipped
+		c.err = fmt.Errorf("variable %s skipped: dependency %s %s", c.name, dep.name, dep.status)
+	}
+	s.finish(c)
+}
+
+// Close stops accepting instructions and waits for the running calcs and
+// those they make ready. The variables that can then still not be computed,
+// because they or one of their dependencies were never defined, fail their
+// prints. Subscriptions end once they have delivered the last changes.
+func (s *Session) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.done
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range s.cells {
+		if c.status != "" {
+			continue
+		}
+		c.status = StatusSkipped
+		if c.defined {
+			c.err = fmt.Errorf("variable %s skipped: dependencies never defined", c.name)
+			s.notify(c)
+		} else {
+			c.err = &UndefinedError{Var: c.name}
+		}
+		for _, f := range c.waiters {
+			s.resolve(f, c)
+		}
+		c.waiters = nil
+	}
+	close(s.done)
+}
+
+// SessionStore keeps sessions under generated IDs and closes those that
+// have not been used for its TTL. It is safe for concurrent use.
+type SessionStore struct {
+	calc *Calculator
+	ttl  time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*storedSession
+}
+
+type storedSession struct {
+	session *Session
+	cancel  context.CancelFunc
+	expires time.Time
+}
+
+// NewSessionStore creates sessions of c that expire ttl after their last
+// use, measured with the clock of c.
+func NewSessionStore(c *Calculator, ttl time.Duration) *SessionStore {
+	return &SessionStore{calc: c, ttl: ttl, sessions: make(map[string]*storedSession)}
+}
+
+func (st *SessionStore) TTL() time.Duration {
+	return st.ttl
+}
+
+// Create starts a session and returns its ID.
+func (st *SessionStore) Create(opts RunOptions) (string, *Session, error) {
+	id, err := newID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := st.calc.NewSession(ctx, opts)
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sessions[id] = &storedSession{session: s, cancel: cancel, expires: st.calc.clock.Now().Add(st.ttl)}
+	return id, s, nil
+}
+
+// Get returns the session with the given ID and extends its lifetime.
+func (st *SessionStore) Get(id string) (*Session, bool) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	stored, ok := st.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	now := st.calc.clock.Now()
+	if !now.Before(stored.expires) {
+		delete(st.sessions, id)
+		go stored.close()
+		return nil, false
+	}
+	stored.expires = now.Add(st.ttl)
+	return stored.session, true
+}
+
+// Delete closes the session with the given ID, canceling its running
+// operations, and reports whether it existed.
+func (st *SessionStore) Delete(id string) bool {
+	st.mu.Lock()
+	stored, ok := st.sessions[id]
+	delete(st.sessions, id)
+	st.mu.Unlock()
+	if ok {
+		stored.close()
+	}
+	return ok
+}
+
+// Expire closes the sessions whose TTL has passed and returns their number.
+// Expired sessions are also dropped by Get, so calling Expire periodically
+// only bounds the memory held by abandoned sessions.
+func (st *SessionStore) Expire() int {
+	now := st.calc.clock.Now()
+	var expired []*storedSession
+	st.mu.Lock()
+	for id, stored := range st.sessions {
+		if !now.Before(stored.expires) {
+			expired = append(expired, stored)
+			delete(st.sessions, id)
+		}
+	}
+	st.mu.Unlock()
+	for _, stored := range expired {
+		stored.close()
+	}
+	return len(expired)
+}
+
+func (s *storedSession) close() {
+	s.cancel()
+	s.session.Close()
+}
@@ -0,0 +1,238 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSession(t *testing.T) {
+	clock := NewVirtualClock(at(0))
+	s := NewCalculator(WithClock(clock)).NewSession(context.Background(), RunOptions{})
+
+	submit := func(instr Instruction) *Future {
+		t.Helper()
+		f, err := s.Submit(instr)
+		if err != nil {
+			t.Fatalf("Submit(%v) failed: %v", instr, err)
+		}
+		return f
+	}
+
+	// b is printed and defined before its dependency a exists.
+	pb := submit(Instruction{Type: "print", Var: "b"})
+	submit(Instruction{Type: "calc", Op: "*", Var: "b", Left: "a", Right: int64(2)})
+	submit(Instruction{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(2)})
+	res, err := pb.Wait(context.Background())
+	if err != nil || res != (Result{Var: "b", Value: 6}) {
+		t.Errorf("print b: got %v, %v", res, err)
+	}
+	if now := clock.Now().Sub(at(0)); now != 2*defaultCost {
+		t.Errorf("expected b after %v, got %v", 2*defaultCost, now)
+	}
+
+	// Variables stay defined for later instructions.
+	submit(Instruction{Type: "calc", Op: "-", Var: "c", Left: "b", Right: "a"})
+	pc := submit(Instruction{Type: "print", Var: "c"})
+	if res, err := pc.Wait(context.Background()); err != nil || res.Value != 3 {
+		t.Errorf("print c: got %v, %v", res, err)
+	}
+
+	rejected := []struct {
+		instr Instruction
+		kind  ValidationKind
+	}{
+		{Instruction{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)}, DuplicateVariable},
+		{Instruction{Type: "calc", Op: "pow", Var: "p", Left: int64(1), Right: int64(1)}, UnknownOperation},
+		{Instruction{Type: "calc", Op: "+", Var: "x", Left: "y", Right: int64(1)}, ""},
+		{Instruction{Type: "calc", Op: "+", Var: "y", Left: "x", Right: int64(1)}, DependencyCycle},
+	}
+	for _, tt := range rejected {
+		_, err := s.Submit(tt.instr)
+		var ve *ValidationError
+		if tt.kind == "" {
+			if err != nil {
+				t.Errorf("Submit(%v) failed: %v", tt.instr, err)
+			}
+		} else if !errors.As(err, &ve) || ve.Kind != tt.kind {
+			t.Errorf("Submit(%v): expected %s, got %v", tt.instr, tt.kind, err)
+		}
+	}
+
+	// x waits for y, which is never defined, and z is computed from a
+	// failing division.
+	px := submit(Instruction{Type: "print", Var: "x"})
+	pu := submit(Instruction{Type: "print", Var: "undefined"})
+	submit(Instruction{Type: "calc", Op: "/", Var: "d", Left: int64(1), Right: int64(0)})
+	submit(Instruction{Type: "calc", Op: "+", Var: "z", Left: "d", Right: int64(1)})
+	pz := submit(Instruction{Type: "print", Var: "z"})
+	if _, err := pz.Wait(context.Background()); err == nil {
+		t.Error("expected error for z depending on a failed division")
+	}
+
+	s.Close()
+	for _, f := range []*Future{px, pu} {
+		select {
+		case <-f.Done():
+			if _, err := f.Result(); err == nil {
+				t.Error("expected error for a variable that is never computed")
+			}
+		default:
+			t.Error("expected print to be resolved by Close")
+		}
+	}
+	if _, err := s.Submit(Instruction{Type: "print", Var: "a"}); err != ErrSessionClosed {
+		t.Errorf("expected ErrSessionClosed, got %v", err)
+	}
+}
+
+func TestSessionWorkers(t *testing.T) {
+	clock := NewVirtualClock(at(0))
+	s := NewCalculator(WithClock(clock), WithWorkers(2)).NewSession(context.Background(), RunOptions{})
+
+	// The test counts as in flight while submitting, so the clock does not
+	// move before all four operations are queued.
+	clock.Begin()
+	var prints []*Future
+	for _, v := range []string{"a", "b", "c", "d"} {
+		s.Submit(Instruction{Type: "calc", Op: "+", Var: v, Left: int64(1), Right: int64(1)})
+		f, _ := s.Submit(Instruction{Type: "print", Var: v})
+		prints = append(prints, f)
+	}
+	clock.End()
+	for _, f := range prints {
+		if _, err := f.Wait(context.Background()); err != nil {
+			t.Fatalf("print failed: %v", err)
+		}
+	}
+	s.Close()
+	if now := clock.Now().Sub(at(0)); now != 2*defaultCost {
+		t.Errorf("expected 4 operations on 2 workers to take %v, got %v", 2*defaultCost, now)
+	}
+}
+
+func TestSessionCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(ctx, RunOptions{})
+	cancel()
+	s.Submit(Instruction{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)})
+	f, _ := s.Submit(Instruction{Type: "print", Var: "a"})
+	s.Close()
+	if _, err := f.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSessionCalculate(t *testing.T) {
+	s := NewCalculator(WithClock(NewVirtualClock(at(0)))).NewSession(context.Background(), RunOptions{})
+	ctx := context.Background()
+
+	results, err := s.Calculate(ctx, []Instruction{
+		{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(2)},
+		{Type: "print", Var: "a"},
+	})
+	if err != nil || !reflect.DeepEqual(results, []Result{{Var: "a", Value: 3}}) {
+		t.Fatalf("first batch: got %v, %v", results, err)
+	}
+
+	results, err = s.Calculate(ctx, []Instruction{
+		{Type: "print", Var: "c"},
+		{Type: "calc", Op: "*", Var: "c", Left: "b", Right: int64(2)},
+		{Type: "calc", Op: "+", Var: "b", Left: "a", Right: int64(1)},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "missing"},
+	})
+	expected := []Result{
+		{Var: "c", Value: 8},
+		{Var: "a", Value: 3},
+		{Var: "missing", Missing: true, Error: "variable missing not defined"},
+	}
+	if err != nil || !reflect.DeepEqual(results, expected) {
+		t.Fatalf("second batch: expected %v, got %v, %v", expected, results, err)
+	}
+
+	// Indexes in errors are positions in the batch, not in the session.
+	rejected := []struct {
+		batch []Instruction
+		kind  ValidationKind
+		index int
+	}{
+		{[]Instruction{
+			{Type: "print", Var: "a"},
+			{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)},
+		}, DuplicateVariable, 1},
+		{[]Instruction{
+			{Type: "calc", Op: "+", Var: "d", Left: int64(1), Right: int64(1)},
+			{Type: "calc", Op: "+", Var: "d", Left: int64(1), Right: int64(1)},
+		}, DuplicateVariable, 1},
+		{[]Instruction{{Type: "calc", Op: "+", Var: "d", Left: "nope", Right: int64(1)}}, UndefinedReference, 0},
+		{[]Instruction{
+			{Type: "print", Var: "a"},
+			{Type: "calc", Op: "+", Var: "d", Left: "e", Right: int64(1)},
+			{Type: "calc", Op: "+", Var: "e", Left: "d", Right: int64(1)},
+		}, DependencyCycle, 1},
+	}
+	for _, tt := range rejected {
+		_, err := s.Calculate(ctx, tt.batch)
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Kind != tt.kind || ve.Index != tt.index {
+			t.Errorf("%v: expected %s at %d, got %v", tt.batch, tt.kind, tt.index, err)
+		}
+	}
+
+	var names []string
+	for _, v := range s.Vars() {
+		if v.Status != StatusOK {
+			t.Errorf("%s: expected ok, got %s", v.Var, v.Status)
+		}
+		names = append(names, v.Var)
+	}
+	if !reflect.DeepEqual(names, []string{"a", "c", "b"}) {
+		t.Errorf("rejected batches must not define variables, got %v", names)
+	}
+
+	_, err = s.Calculate(ctx, []Instruction{
+		{Type: "print", Var: "a"},
+		{Type: "calc", Op: "/", Var: "z", Left: "a", Right: int64(0)},
+		{Type: "print", Var: "z"},
+	})
+	var oe *OperationError
+	if !errors.As(err, &oe) || !errors.Is(err, ErrDivisionByZero) || oe.Index != 1 {
+		t.Errorf("expected division by zero at 1, got %v", err)
+	}
+	s.Close()
+}
+
+func TestSessionStore(t *testing.T) {
+	clock := NewVirtualClock(at(0))
+	st := NewSessionStore(NewCalculator(WithClock(clock)), time.Minute)
+
+	id, _, err := st.Create(RunOptions{})
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	other, _, _ := st.Create(RunOptions{})
+	clock.Advance(50 * time.Second)
+	if _, ok := st.Get(id); !ok {
+		t.Fatal("session expired before its TTL")
+	}
+	clock.Advance(50 * time.Second)
+	if _, ok := st.Get(id); !ok {
+		t.Error("Get must extend the lifetime of a session")
+	}
+	if n := st.Expire(); n != 1 {
+		t.Errorf("expected 1 expired session, got %d", n)
+	}
+	if _, ok := st.Get(other); ok {
+		t.Error("expected unused session to expire")
+	}
+
+	if !st.Delete(id) || st.Delete(id) {
+		t.Error("expected Delete to report whether the session existed")
+	}
+	if _, ok := st.Get(id); ok {
+		t.Error("expected deleted session to be gone")
+	}
+}
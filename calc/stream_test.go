@@ -0,0 +1,115 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExecuteStream(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("slow", 2, add, 30*time.Millisecond)
+	r.Register("fast", 2, add, 10*time.Millisecond)
+	r.Register("fail", 2, func([]int64) (int64, error) { return 0, errors.New("boom") }, 20*time.Millisecond)
+
+	type emitted struct {
+		Result
+		at time.Duration
+	}
+	run := func(instructions []Instruction) ([]emitted, *Report, error) {
+		clock := NewVirtualClock(at(0))
+		var got []emitted
+		report, err := NewCalculator(WithRegistry(r), WithClock(clock)).ExecuteStream(context.Background(), instructions, RunOptions{}, func(res Result) error {
+			got = append(got, emitted{res, clock.Now().Sub(at(0))})
+			return nil
+		})
+		return got, report, err
+	}
+
+	got, report, err := run([]Instruction{
+		{Type: "calc", Op: "slow", Var: "b", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "fast", Var: "a", Left: int64(3), Right: int64(4)},
+		{Type: "print", Var: "b"},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "b"},
+	})
+	if err != nil {
+		t.Fatalf("ExecuteStream failed: %v", err)
+	}
+	expected := []emitted{
+		{Result{Var: "a", Value: 7}, 10 * time.Millisecond},
+		{Result{Var: "b", Value: 3}, 30 * time.Millisecond},
+		{Result{Var: "b", Value: 3}, 30 * time.Millisecond},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+	if want := []Result{{Var: "b", Value: 3}, {Var: "a", Value: 7}, {Var: "b", Value: 3}}; !reflect.DeepEqual(report.Results, want) {
+		t.Errorf("expected report results in print order %v, got %v", want, report.Results)
+	}
+
+	got, _, err = run([]Instruction{
+		{Type: "calc", Op: "fast", Var: "a", Left: int64(3), Right: int64(4)},
+		{Type: "calc", Op: "fail", Var: "f", Left: int64(1), Right: int64(2)},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "f"},
+	})
+	if err == nil {
+		t.Error("expected error from failing operation")
+	}
+	if len(got) != 1 || got[0].Var != "a" {
+		t.Errorf("expected a to be streamed before the failure, got %v", got)
+	}
+}
+
+func TestExecuteStreamSlowConsumer(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("+", 2, add, 0)
+	computed := make(chan struct{})
+	r.Register("signal", 2, func(args []int64) (int64, error) {
+		close(computed)
+		return add(args)
+	}, 0)
+
+	// b only starts once a is done; it must not wait for a to be emitted.
+	report, err := NewCalculator(WithRegistry(r)).ExecuteStream(context.Background(), []Instruction{
+		{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "signal", Var: "b", Left: "a", Right: int64(1)},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "b"},
+	}, RunOptions{}, func(res Result) error {
+		if res.Var == "a" {
+			select {
+			case <-computed:
+			case <-time.After(5 * time.Second):
+				return errors.New("b was not computed while a was emitted")
+			}
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(report.Results, []Result{{Var: "a", Value: 3}, {Var: "b", Value: 4}}) {
+		t.Errorf("got %v, %v", report, err)
+	}
+}
+
+func TestExecuteStreamEmitError(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("+", 2, add, 0)
+	r.Register("slow", 2, add, time.Hour)
+	gone := errors.New("client gone")
+
+	report, err := NewCalculator(WithRegistry(r)).ExecuteStream(context.Background(), []Instruction{
+		{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "slow", Var: "s", Left: int64(1), Right: int64(2)},
+		{Type: "print", Var: "a"},
+		{Type: "print", Var: "s"},
+	}, RunOptions{}, func(Result) error { return gone })
+	if err != gone {
+		t.Fatalf("expected the emit error, got %v", err)
+	}
+	if st := report.Statuses[1]; st.Var != "s" || st.Status != StatusCanceled {
+		t.Errorf("expected s to be canceled, got %+v", st)
+	}
+}
@@ -0,0 +1,108 @@
+package calc
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// trace returns the spans of the executed instructions in instruction order.
+func (e *execution) trace(calcOps []Instruction, started time.Time) []Span {
+	var spans []Span
+	for _, instr := range calcOps {
+		n := e.nodes[instr.Var]
+		if n.start.IsZero() {
+			continue
+		}
+
+		deps := getDependencies(instr)
+		sort.SliceStable(deps, func(i, j int) bool {
+			return e.nodes[deps[i]].end.Before(e.nodes[deps[j]].end)
+		})
+		var waits []Wait
+		prev := started
+		seen := make(map[string]bool)
+		for _, dep := range deps {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			end := e.nodes[dep].end
+			waits = append(waits, Wait{Dep: dep, Duration: max(end.Sub(prev), 0)})
+			if end.After(prev) {
+				prev = end
+			}
+		}
+
+		spans = append(spans, Span{
+			Index:  n.index,
+			Var:    instr.Var,
+			Op:     instr.Op,
+			Status: n.status,
+			Worker: n.worker,
+			Start:  n.start,
+			End:    n.end,
+			Queued: n.start.Sub(n.ready),
+			Waits:  waits,
+		})
+	}
+	return spans
+}
+
+// ChromeTrace is a trace in the Chrome trace event format, which profilers
+// like chrome://tracing and Perfetto load. Every span becomes a complete
+// event on the thread of its worker.
+type ChromeTrace struct {
+	TraceEvents     []ChromeEvent `json:"traceEvents"`
+	DisplayTimeUnit string        `json:"displayTimeUnit"`
+}
+
+type ChromeEvent struct {
+	Name string                 `json:"name"`
+	Cat  string                 `json:"cat,omitempty"`
+	Ph   string                 `json:"ph"`
+	Ts   int64                  `json:"ts"`
+	Dur  int64                  `json:"dur,omitempty"`
+	Pid  int                    `json:"pid"`
+	Tid  int                    `json:"tid"`
+	Args map[string]interface{} `json:"args,omitempty"`
+}
+
+// NewChromeTrace converts the trace of a report, with timestamps in
+// microseconds since the start of the run.
+func NewChromeTrace(r *Report) *ChromeTrace {
+	t := &ChromeTrace{TraceEvents: []ChromeEvent{}, DisplayTimeUnit: "ms"}
+	workers := make(map[int]bool)
+	for _, s := range r.Trace {
+		if !workers[s.Worker] {
+			workers[s.Worker] = true
+			t.TraceEvents = append(t.TraceEvents, ChromeEvent{
+				Name: "thread_name",
+				Ph:   "M",
+				Pid:  1,
+				Tid:  s.Worker,
+				Args: map[string]interface{}{"name": fmt.Sprintf("worker %d", s.Worker)},
+			})
+		}
+
+		args := map[string]interface{}{
+			"index":     s.Index,
+			"status":    s.Status,
+			"queued_us": s.Queued.Microseconds(),
+		}
+		for _, w := range s.Waits {
+			args["wait "+w.Dep+" (us)"] = w.Duration.Microseconds()
+		}
+		t.TraceEvents = append(t.TraceEvents, ChromeEvent{
+			Name: s.Var,
+			Cat:  s.Op,
+			Ph:   "X",
+			Ts:   s.Start.Sub(r.Started).Microseconds(),
+			Dur:  s.End.Sub(s.Start).Microseconds(),
+			Pid:  1,
+			Tid:  s.Worker,
+			Args: args,
+		})
+	}
+	return t
+}
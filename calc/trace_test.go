@@ -0,0 +1,65 @@
+package calc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrace(t *testing.T) {
+	r := NewOperationRegistry()
+	r.Register("slow", 2, add, 30*time.Millisecond)
+	r.Register("fast", 2, add, 10*time.Millisecond)
+	instructions := []Instruction{
+		{Type: "calc", Op: "fast", Var: "y", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "slow", Var: "x", Left: int64(1), Right: int64(2)},
+		{Type: "calc", Op: "fast", Var: "z", Left: "x", Right: "y"},
+		{Type: "print", Var: "z"},
+	}
+	ms := time.Millisecond
+
+	tests := []struct {
+		workers int
+		spans   []Span
+	}{
+		{0, []Span{
+			{Index: 0, Var: "y", Op: "fast", Status: StatusOK, Worker: 1, Start: at(0), End: at(10 * ms)},
+			{Index: 1, Var: "x", Op: "slow", Status: StatusOK, Worker: 0, Start: at(0), End: at(30 * ms)},
+			{Index: 2, Var: "z", Op: "fast", Status: StatusOK, Worker: 0, Start: at(30 * ms), End: at(40 * ms),
+				Waits: []Wait{{"y", 10 * ms}, {"x", 20 * ms}}},
+		}},
+		{1, []Span{
+			{Index: 0, Var: "y", Op: "fast", Status: StatusOK, Worker: 0, Start: at(30 * ms), End: at(40 * ms), Queued: 30 * ms},
+			{Index: 1, Var: "x", Op: "slow", Status: StatusOK, Worker: 0, Start: at(0), End: at(30 * ms)},
+			{Index: 2, Var: "z", Op: "fast", Status: StatusOK, Worker: 0, Start: at(40 * ms), End: at(50 * ms),
+				Waits: []Wait{{"x", 30 * ms}, {"y", 10 * ms}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		calc := NewCalculator(WithRegistry(r), WithClock(NewVirtualClock(at(0))), WithWorkers(tt.workers))
+		report, err := calc.Execute(context.Background(), instructions, RunOptions{Trace: true})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if !reflect.DeepEqual(report.Trace, tt.spans) {
+			t.Errorf("workers %d: unexpected trace\n got %+v\nwant %+v", tt.workers, report.Trace, tt.spans)
+		}
+
+		chrome := NewChromeTrace(report)
+		last := chrome.TraceEvents[len(chrome.TraceEvents)-1]
+		if last.Name != "z" || last.Ph != "X" || last.Ts != report.Trace[2].Start.Sub(at(0)).Microseconds() || last.Dur != 10000 {
+			t.Errorf("workers %d: unexpected chrome event %+v", tt.workers, last)
+		}
+	}
+
+	report, _ := NewCalculator(WithRegistry(r), WithClock(NewVirtualClock(at(0)))).Execute(context.Background(), instructions, RunOptions{})
+	if report.Trace != nil {
+		t.Errorf("expected no trace unless requested, got %v", report.Trace)
+	}
+}
+
+func at(d time.Duration) time.Time {
+	return time.Unix(0, 0).Add(d)
+}
@@ -0,0 +1,184 @@
+package calc
+
+import (
+	"fmt"
+	"strings"
+)
+
+type ValidationKind string
+
+const (
+	UndefinedReference   ValidationKind = "undefined reference"
+	SelfReference        ValidationKind = "self reference"
+	DuplicateVariable    ValidationKind = "duplicate variable"
+	DependencyCycle      ValidationKind = "dependency cycle"
+	UnknownOperation     ValidationKind = "unknown operation"
+	ArityMismatch        ValidationKind = "arity mismatch"
+	UnsupportedOperation ValidationKind = "unsupported operation"
+	InvalidLiteral       ValidationKind = "invalid literal"
+	ReservedName         ValidationKind = "reserved name"
+	InvalidName          ValidationKind = "invalid name"
+)
+
+// ValidationError describes a problem found in the dependency graph of a
+// batch before any instruction is executed. Index is the position of the
+// offending instruction in the original list.
+type ValidationError struct {
+	Kind   ValidationKind
+	Index  int
+	Var    string
+	Ref    string
+	Cycle  []string
+	Op     string
+	Arity  int
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	switch e.Kind {
+	case UndefinedReference:
+		return fmt.Sprintf("instruction %d: variable %s references undefined variable %s", e.Index, e.Var, e.Ref)
+	case SelfReference:
+		return fmt.Sprintf("instruction %d: variable %s references itself", e.Index, e.Var)
+	case DuplicateVariable:
+		return fmt.Sprintf("instruction %d: variable %s already exists", e.Index, e.Var)
+	case DependencyCycle:
+		return fmt.Sprintf("instruction %d: dependency cycle %s", e.Index, strings.Join(e.Cycle, " -> "))
+	case UnknownOperation:
+		return fmt.Sprintf("instruction %d: unknown operation %s", e.Index, e.Op)
+	case ArityMismatch:
+		return fmt.Sprintf("instruction %d: operation %s takes %d operand(s)", e.Index, e.Op, e.Arity)
+	case UnsupportedOperation:
+		return fmt.Sprintf("instruction %d: operation %s has no %s implementation", e.Index, e.Op, e.Reason)
+	case InvalidLiteral:
+		return fmt.Sprintf("instruction %d: invalid literal: %s", e.Index, e.Reason)
+	case ReservedName:
+		return fmt.Sprintf("instruction %d: variable name %s contains the version separator %s", e.Index, e.Var, versionSep)
+	case InvalidName:
+		return fmt.Sprintf("instruction %d: variable name %s reads as a literal or parameter", e.Index, e.Var)
+	}
+	return fmt.Sprintf("instruction %d: invalid variable %s", e.Index, e.Var)
+}
+
+// graph is the dependency graph of the calc instructions of a batch.
+type graph struct {
+	order []string
+	index map[string]int
+	deps  map[string][]string
+}
+
+func buildGraph(instructions []Instruction) (*graph, error) {
+	g := &graph{
+		index: make(map[string]int),
+		deps:  make(map[string][]string),
+	}
+
+	for i, instr := range instructions {
+		if instr.Type != "calc" {
+			continue
+		}
+		if err := checkName(i, instr.Var); err != nil {
+			return nil, err
+		}
+		if _, exists := g.index[instr.Var]; exists {
+			return nil, &ValidationError{Kind: DuplicateVariable, Index: i, Var: instr.Var}
+		}
+		g.index[instr.Var] = i
+		g.order = append(g.order, instr.Var)
+		g.deps[instr.Var] = getDependencies(instr)
+	}
+
+	for _, v := range g.order {
+		for _, dep := range g.deps[v] {
+			if dep == v {
+				return nil, &ValidationError{Kind: SelfReference, Index: g.index[v], Var: v, Ref: dep}
+			}
+			if _, ok := g.index[dep]; !ok {
+				return nil, &ValidationError{Kind: UndefinedReference, Index: g.index[v], Var: v, Ref: dep}
+			}
+		}
+	}
+
+	if cycle := g.findCycle(); cycle != nil {
+		return nil, &ValidationError{Kind: DependencyCycle, Index: g.index[cycle[0]], Var: cycle[0], Cycle: cycle}
+	}
+
+	return g, nil
+}
+
+// checkName rejects a calc variable that no operand could reference because
+// its name, such as "5" or "$x", reads as a literal or parameter.
+func checkName(index int, name string) error {
+	if IsLiteral(name) || isParam(name) {
+		return &ValidationError{Kind: InvalidName, Index: index, Var: name}
+	}
+	return nil
+}
+
+// findCycle returns the first cycle reachable in instruction order as a path
+// that starts and ends with the same variable, or nil if the graph is acyclic.
+func (g *graph) findCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(g.order))
+	var stack []string
+
+	var visit func(v string) []string
+	visit = func(v string) []string {
+		state[v] = visiting
+		stack = append(stack, v)
+		for _, dep := range g.deps[v] {
+			switch state[dep] {
+			case visiting:
+				for i, s := range stack {
+					if s == dep {
+						return append(append([]string{}, stack[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[v] = visited
+		return nil
+	}
+
+	for _, v := range g.order {
+		if state[v] == unvisited {
+			if cycle := visit(v); cycle != nil {
+				return cycle
+			}
+		}
+	}
+	return nil
+}
+
+// required returns the calc variables that the given print instructions
+// depend on, directly or transitively.
+func (g *graph) required(printOps []Instruction) map[string]bool {
+	needed := make(map[string]bool)
+	var stack []string
+	for _, instr := range printOps {
+		if _, ok := g.index[instr.Var]; ok && !needed[instr.Var] {
+			needed[instr.Var] = true
+			stack = append(stack, instr.Var)
+		}
+	}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, dep := range g.deps[v] {
+			if !needed[dep] {
+				needed[dep] = true
+				stack = append(stack, dep)
+			}
+		}
+	}
+	return needed
+}
@@ -0,0 +1,120 @@
+package calc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildGraphErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		instructions []Instruction
+		kind         ValidationKind
+		index        int
+		cycle        []string
+	}{
+		{
+			name: "undefined reference",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: "missing"},
+				{Type: "print", Var: "x"},
+			},
+			kind:  UndefinedReference,
+			index: 0,
+		},
+		{
+			name: "self reference",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)},
+				{Type: "calc", Op: "+", Var: "x", Left: "x", Right: int64(1)},
+			},
+			kind:  SelfReference,
+			index: 1,
+		},
+		{
+			name: "duplicate variable",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(2)},
+				{Type: "print", Var: "x"},
+				{Type: "calc", Op: "*", Var: "x", Left: int64(3), Right: int64(4)},
+			},
+			kind:  DuplicateVariable,
+			index: 2,
+		},
+		{
+			name: "literal name",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(2)},
+				{Type: "calc", Op: "+", Var: "1.5", Left: int64(1), Right: int64(2)},
+			},
+			kind:  InvalidName,
+			index: 1,
+		},
+		{
+			name: "parameter name",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "$x", Left: int64(1), Right: int64(2)},
+			},
+			kind:  InvalidName,
+			index: 0,
+		},
+		{
+			name: "cycle",
+			instructions: []Instruction{
+				{Type: "calc", Op: "+", Var: "a", Left: int64(1), Right: int64(1)},
+				{Type: "calc", Op: "+", Var: "x", Left: "y", Right: int64(1)},
+				{Type: "calc", Op: "+", Var: "y", Left: "z", Right: "a"},
+				{Type: "calc", Op: "+", Var: "z", Left: "x", Right: int64(1)},
+			},
+			kind:  DependencyCycle,
+			index: 1,
+			cycle: []string{"x", "y", "z", "x"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := buildGraph(tt.instructions)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if verr.Kind != tt.kind {
+				t.Errorf("expected kind %q, got %q", tt.kind, verr.Kind)
+			}
+			if verr.Index != tt.index {
+				t.Errorf("expected index %d, got %d", tt.index, verr.Index)
+			}
+			if tt.cycle != nil && !reflect.DeepEqual(verr.Cycle, tt.cycle) {
+				t.Errorf("expected cycle %v, got %v", tt.cycle, verr.Cycle)
+			}
+		})
+	}
+}
+
+func TestCalculateRejectsInvalidGraph(t *testing.T) {
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: "y", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "y", Left: "x", Right: int64(1)},
+		{Type: "calc", Op: "+", Var: "z", Left: "undefined", Right: int64(1)},
+		{Type: "print", Var: "x"},
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := NewCalculator(WithClock(NewVirtualClock(at(0)))).Calculate(instructions)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Calculate did not return for an invalid graph")
+	}
+}
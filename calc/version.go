@@ -0,0 +1,79 @@
+package calc
+
+import (
+	"strconv"
+	"strings"
+)
+
+// versionSep separates a variable name from its version in versioned
+// batches, as in "x#2".
+const versionSep = "#"
+
+// versionInstructions turns a batch that may assign a variable several times
+// into one that assigns every variable once: each assignment of x defines a
+// new version x#1, x#2, ... and each reference to x, in an operand or a
+// print, is renamed to the latest version assigned before it. Versions only
+// depend on what they reference, so independent versions of a variable are
+// computed in parallel.
+func versionInstructions(instructions []Instruction) ([]Instruction, error) {
+	latest := make(map[string]int)
+	versioned := make([]Instruction, len(instructions))
+	for i, instr := range instructions {
+		if instr.Type == "calc" {
+			if err := checkName(i, instr.Var); err != nil {
+				return nil, err
+			}
+			if strings.Contains(instr.Var, versionSep) {
+				return nil, &ValidationError{Kind: ReservedName, Index: i, Var: instr.Var}
+			}
+		}
+		for _, operand := range []*interface{}{&instr.Left, &instr.Right} {
+			if !isVariable(*operand) {
+				continue
+			}
+			name := (*operand).(string)
+			if latest[name] == 0 {
+				return nil, &ValidationError{Kind: UndefinedReference, Index: i, Var: instr.Var, Ref: name}
+			}
+			*operand = versionName(name, latest[name])
+		}
+		switch instr.Type {
+		case "calc":
+			latest[instr.Var]++
+			instr.Var = versionName(instr.Var, latest[instr.Var])
+		case "print":
+			if latest[instr.Var] > 0 {
+				instr.Var = versionName(instr.Var, latest[instr.Var])
+			}
+		}
+		versioned[i] = instr
+	}
+	return versioned, nil
+}
+
+func versionName(name string, version int) string {
+	return name + versionSep + strconv.Itoa(version)
+}
+
+// splitVersion is the inverse of versionName.
+func splitVersion(name string) (string, int) {
+	i := strings.LastIndex(name, versionSep)
+	if i < 0 {
+		return name, 0
+	}
+	version, err := strconv.Atoi(name[i+1:])
+	if err != nil {
+		return name, 0
+	}
+	return name[:i], version
+}
+
+// result is the Result of a print of the variable name. In versioned
+// programs it reports the original name and the version printed.
+func (p *Program) result(name string, val interface{}) Result {
+	res := newResult(name, val)
+	if p.opts.Versioned {
+		res.Var, res.Version = splitVersion(name)
+	}
+	return res
+}
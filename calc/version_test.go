@@ -0,0 +1,65 @@
+package calc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestVersionedBatch(t *testing.T) {
+	clock := NewVirtualClock(at(0))
+	c := NewCalculator(WithClock(clock))
+	instructions := []Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(1), Right: int64(1)},
+		{Type: "calc", Op: "*", Var: "y", Left: "x", Right: int64(10)},
+		{Type: "print", Var: "x"},
+		{Type: "calc", Op: "+", Var: "x", Left: "x", Right: int64(5)},
+		{Type: "calc", Op: "*", Var: "z", Left: "x", Right: int64(10)},
+		{Type: "print", Var: "y"},
+		{Type: "print", Var: "x"},
+		{Type: "print", Var: "z"},
+	}
+
+	if _, err := c.Calculate(instructions); err == nil {
+		t.Error("expected duplicate variable error without versioning")
+	}
+
+	report, err := c.Execute(context.Background(), instructions, RunOptions{Versioned: true})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	expected := []Result{
+		{Var: "x", Value: 2, Version: 1},
+		{Var: "y", Value: 20, Version: 1},
+		{Var: "x", Value: 7, Version: 2},
+		{Var: "z", Value: 70, Version: 1},
+	}
+	if !reflect.DeepEqual(report.Results, expected) {
+		t.Errorf("expected %v, got %v", expected, report.Results)
+	}
+	// y and the second version of x only depend on x#1 and run in parallel.
+	if d := clock.Now().Sub(at(0)); d != 3*defaultCost {
+		t.Errorf("expected makespan %v, got %v", 3*defaultCost, d)
+	}
+
+	invalid := []struct {
+		instructions []Instruction
+		kind         ValidationKind
+	}{
+		{[]Instruction{
+			{Type: "calc", Op: "+", Var: "a", Left: "b", Right: int64(1)},
+			{Type: "calc", Op: "+", Var: "b", Left: int64(1), Right: int64(1)},
+		}, UndefinedReference},
+		{[]Instruction{{Type: "calc", Op: "+", Var: "a", Left: "a", Right: int64(1)}}, UndefinedReference},
+		{[]Instruction{{Type: "calc", Op: "+", Var: "a#1", Left: int64(1), Right: int64(1)}}, ReservedName},
+		{[]Instruction{{Type: "calc", Op: "+", Var: "2", Left: int64(1), Right: int64(1)}}, InvalidName},
+	}
+	for _, tt := range invalid {
+		_, err := c.Execute(context.Background(), tt.instructions, RunOptions{Versioned: true})
+		var ve *ValidationError
+		if !errors.As(err, &ve) || ve.Kind != tt.kind {
+			t.Errorf("%v: expected %s, got %v", tt.instructions, tt.kind, err)
+		}
+	}
+}
@@ -17,48 +17,720 @@ const docTemplate = `{
     "paths": {
         "/calculate": {
             "post": {
-                "description": "Perform a batch of calculations with 50ms delay per operation",
+                "description": "Perform a batch of calculations. Every operation takes its simulated latency, 50ms by default.\nOperands are integers, variable names or decimal strings such as \"12.345\".\nWith Content-Type text/plain the body is a program in the infix language, e.g. \"x = 1 + 2; print x\".\nWith Accept application/x-ndjson or text/event-stream every print result is sent as soon as it is computed,\nas {\"result\": ...} lines or result events, followed by a summary with all results and an error, if any.\nErrors are otherwise returned as application/problem+json with the calc error code and the offending instruction.",
                 "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Calculator"
+                ],
+                "summary": "Calculate operations",
+                "parameters": [
+                    {
+                        "description": "Array of calculation instructions",
+                        "name": "instructions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calc.Instruction"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Evaluate every instruction, including those no print depends on",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compute with arbitrary-precision integers; exact values are returned in the exact field",
+                        "name": "big",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow assigning a variable several times, each assignment defining a new version",
+                        "name": "versioned",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "final",
+                            "program"
+                        ],
+                        "type": "string",
+                        "description": "Value reported by a print: final, the default, or program for only variables defined before the print",
+                        "name": "print",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the execution trace of every calc instruction",
+                        "name": "trace",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum duration of the calculation, e.g. 500ms",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ResponseWrapper"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Variable assigned twice",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid instruction or failed operation",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Timeout exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/calculate/plan": {
+            "post": {
+                "description": "Return the dependency graph of a batch without running it: the levels of instructions that run concurrently, the critical path and the pruned instructions.\nWith format=dot or format=mermaid the graph is also rendered for Graphviz or Mermaid.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "Calculator"
+                ],
+                "summary": "Explain a batch",
+                "parameters": [
+                    {
+                        "description": "Array of calculation instructions",
+                        "name": "instructions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calc.Instruction"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Evaluate every instruction, including those no print depends on",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compute with arbitrary-precision integers",
+                        "name": "big",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow assigning a variable several times, each assignment defining a new version",
+                        "name": "versioned",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "final",
+                            "program"
+                        ],
+                        "type": "string",
+                        "description": "Value reported by a print: final, the default, or program for only variables defined before the print",
+                        "name": "print",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "dot",
+                            "mermaid"
+                        ],
+                        "type": "string",
+                        "description": "Rendering of the graph",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.PlanResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Variable assigned twice",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid instruction",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/calculate/trace": {
+            "post": {
+                "description": "Run a batch and return its execution trace in the Chrome trace event format, which chrome://tracing and Perfetto can load.\nThe trace is returned also when an instruction fails; the status of every span tells which.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calculator"
+                ],
+                "summary": "Trace a batch",
+                "parameters": [
+                    {
+                        "description": "Array of calculation instructions",
+                        "name": "instructions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calc.Instruction"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Evaluate every instruction, including those no print depends on",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compute with arbitrary-precision integers",
+                        "name": "big",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow assigning a variable several times, each assignment defining a new version",
+                        "name": "versioned",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "final",
+                            "program"
+                        ],
+                        "type": "string",
+                        "description": "Value reported by a print: final, the default, or program for only variables defined before the print",
+                        "name": "print",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/calc.ChromeTrace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Variable assigned twice",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid instruction",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/operations": {
+            "get": {
+                "description": "List the operations accepted in calc instructions with their estimated latency",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Calculator"
+                ],
+                "summary": "List operations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.OperationsWrapper"
+                        }
+                    }
+                }
+            }
+        },
+        "/programs": {
+            "post": {
+                "description": "Validate and plan a batch once and store it for repeated runs.\nOperands of the form \"$name\" are parameters bound on every run.\nestimate_ms predicts the duration of a run from the latency models of the operations.\nThe program expires when it has not been run for the -program-ttl of the server.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Register a program",
+                "parameters": [
+                    {
+                        "description": "Array of calculation instructions",
+                        "name": "instructions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calc.Instruction"
+                            }
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Evaluate every instruction, including those no print depends on",
+                        "name": "full",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Compute with arbitrary-precision integers",
+                        "name": "big",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow assigning a variable several times, each assignment defining a new version",
+                        "name": "versioned",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "final",
+                            "program"
+                        ],
+                        "type": "string",
+                        "description": "Value reported by a print: final, the default, or program for only variables defined before the print",
+                        "name": "print",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.ProgramInfo"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Variable assigned twice",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid instruction",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}": {
+            "delete": {
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Delete a program",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Program not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/programs/{id}/run": {
+            "post": {
+                "description": "Run a registered program with the given parameter values, keyed by name without the \"$\".",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Programs"
+                ],
+                "summary": "Run a program",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Program ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Parameter values",
+                        "name": "params",
+                        "in": "body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum duration of the run, e.g. 500ms",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ResponseWrapper"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Program not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Failed operation",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Timeout exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "post": {
+                "description": "Create a variable namespace that lives across requests. Variables are write-once within a session.\nThe session expires ttl_seconds after its last use.\nIn a reactive session calcs may redefine variables, and the variables depending on them are recomputed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Create a session",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Compute with arbitrary-precision integers",
+                        "name": "big",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Allow redefining variables",
+                        "name": "reactive",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.SessionInfo"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal error",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "delete": {
+                "description": "Delete a session and its variables, canceling its running operations.",
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Delete a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/calculate": {
+            "post": {
+                "description": "Run a batch in a session. Its instructions may reference the variables defined by earlier batches of the session.\nA rejected batch defines no variable.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Calculate in a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Array of calculation instructions",
+                        "name": "instructions",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/calc.Instruction"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Maximum time to wait for the prints, e.g. 500ms",
+                        "name": "timeout",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ResponseWrapper"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "409": {
+                        "description": "Variable already defined",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "422": {
+                        "description": "Invalid instruction or failed operation",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    },
+                    "504": {
+                        "description": "Timeout exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/vars": {
+            "get": {
+                "description": "List the variables of a session in definition order with their status and, once computed, value.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Calculator"
+                    "Sessions"
                 ],
-                "summary": "Calculate operations",
+                "summary": "List session variables",
                 "parameters": [
                     {
-                        "description": "Array of calculation instructions",
-                        "name": "instructions",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/calc.Instruction"
-                            }
-                        }
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ResponseWrapper"
+                            "$ref": "#/definitions/main.VariablesWrapper"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request format",
+                    "404": {
+                        "description": "Session not found",
                         "schema": {
+                            "$ref": "#/definitions/main.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/watch": {
+            "get": {
+                "description": "Stream the state of session variables as server-sent change events: first of those already computed,\nthen every time one is computed or fails. In a reactive session this includes every recomputation\nafter a redefinition. The stream ends when the session is deleted or expires.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Watch session variables",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
                             "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Variables to watch, all if omitted",
+                        "name": "var",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.VariableInfo"
                         }
                     },
-                    "500": {
-                        "description": "Internal calculation error",
+                    "404": {
+                        "description": "Session not found",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.Problem"
                         }
                     }
                 }
@@ -66,6 +738,50 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "calc.ChromeEvent": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "cat": {
+                    "type": "string"
+                },
+                "dur": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ph": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "integer"
+                },
+                "tid": {
+                    "type": "integer"
+                },
+                "ts": {
+                    "type": "integer"
+                }
+            }
+        },
+        "calc.ChromeTrace": {
+            "type": "object",
+            "properties": {
+                "displayTimeUnit": {
+                    "type": "string"
+                },
+                "traceEvents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/calc.ChromeEvent"
+                    }
+                }
+            }
+        },
         "calc.Instruction": {
             "type": "object",
             "properties": {
@@ -85,11 +801,172 @@ const docTemplate = `{
         "calc.Result": {
             "type": "object",
             "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "exact": {
+                    "type": "string"
+                },
+                "missing": {
+                    "description": "Missing is set for a print that has no value, with Error telling why:\nits variable is never defined or, in program order, only after it.",
+                    "type": "boolean"
+                },
                 "value": {
                     "type": "integer"
                 },
                 "var": {
                     "type": "string"
+                },
+                "version": {
+                    "description": "Version is the version of Var printed in versioned batches.",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.OperationInfo": {
+            "type": "object",
+            "properties": {
+                "arity": {
+                    "type": "integer"
+                },
+                "big_int": {
+                    "type": "boolean"
+                },
+                "cost_ms": {
+                    "type": "integer"
+                },
+                "decimal": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.OperationsWrapper": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.OperationInfo"
+                    }
+                }
+            }
+        },
+        "main.PlanNodeInfo": {
+            "type": "object",
+            "properties": {
+                "deps": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "estimate_ms": {
+                    "type": "integer"
+                },
+                "expr": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "integer"
+                },
+                "pruned": {
+                    "type": "boolean"
+                },
+                "var": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.PlanResponse": {
+            "type": "object",
+            "properties": {
+                "critical_path": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "critical_path_ms": {
+                    "type": "integer"
+                },
+                "estimate_ms": {
+                    "type": "integer"
+                },
+                "levels": {
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "nodes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.PlanNodeInfo"
+                    }
+                },
+                "pruned": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rendering": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "detail": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "instance": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "var": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ProgramInfo": {
+            "type": "object",
+            "properties": {
+                "estimate_ms": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -101,6 +978,103 @@ const docTemplate = `{
                     "items": {
                         "$ref": "#/definitions/calc.Result"
                     }
+                },
+                "pruned": {
+                    "type": "integer"
+                },
+                "trace": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.SpanInfo"
+                    }
+                }
+            }
+        },
+        "main.SessionInfo": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "ttl_seconds": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.SpanInfo": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "queued_us": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "var": {
+                    "type": "string"
+                },
+                "waits": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.WaitInfo"
+                    }
+                },
+                "worker": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.VariableInfo": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "exact": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                },
+                "var": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.VariablesWrapper": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.VariableInfo"
+                    }
+                }
+            }
+        },
+        "main.WaitInfo": {
+            "type": "object",
+            "properties": {
+                "dep": {
+                    "type": "string"
+                },
+                "wait_us": {
+                    "type": "integer"
                 }
             }
         }
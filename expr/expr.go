@@ -0,0 +1,347 @@
+// Package expr compiles a small infix language into calc instructions:
+//
+//	x = 10 + 2
+//	z = (x - 3) * y
+//	m = max(z, 0) ** 2
+//	print z
+//
+// Statements are separated by newlines or semicolons and // starts a comment.
+// Operators bind, from loosest to tightest: + -, then * / %, then unary -,
+// then the right-associative **. Other operations of the calculator are
+// written as calls, e.g. min(a, b). Nested expressions are lowered into
+// temporary variables named .t1, .t2, ... which cannot clash with
+// identifiers of the language. Programs that share variables, such as the
+// batches of a session, give each its own prefix with ParseTemps.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"prac/calc"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError reports the position of invalid input, counting lines and
+// columns from 1.
+type SyntaxError struct {
+	Line int
+	Col  int
+	Msg  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Col, e.Msg)
+}
+
+// Parse compiles src into a list of calc instructions.
+func Parse(src string) ([]calc.Instruction, error) {
+	return ParseTemps(src, ".t")
+}
+
+// ParseTemps is like Parse but names the temporary variables prefix1,
+// prefix2, ... The prefix must start with a dot so that they cannot clash
+// with identifiers.
+func ParseTemps(src, prefix string) ([]calc.Instruction, error) {
+	if !strings.HasPrefix(prefix, ".") {
+		return nil, fmt.Errorf("temporary prefix %q does not start with a dot", prefix)
+	}
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	stmts, err := p.program()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &lowerer{prefix: prefix}
+	for _, st := range stmts {
+		if st.print {
+			l.out = append(l.out, calc.Instruction{Type: "print", Var: st.name})
+			continue
+		}
+		l.assign(st.name, st.value)
+	}
+	return l.out, nil
+}
+
+type statement struct {
+	print bool
+	name  string
+	value node
+}
+
+// node is an expression: a literal, a variable or an operation on operands.
+type node struct {
+	literal  interface{}
+	variable string
+	op       string
+	args     []node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) errorf(t token, format string, args ...interface{}) error {
+	return &SyntaxError{Line: t.line, Col: t.col, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) expect(kind tokenKind, text string) (token, error) {
+	t := p.next()
+	if t.kind != kind || (text != "" && t.text != text) {
+		want := text
+		if want == "" {
+			want = kind.String()
+		}
+		return t, p.errorf(t, "expected %s, found %s", want, t)
+	}
+	return t, nil
+}
+
+func (p *parser) program() ([]statement, error) {
+	var stmts []statement
+	for {
+		for p.peek().kind == tokSeparator {
+			p.next()
+		}
+		if p.peek().kind == tokEOF {
+			return stmts, nil
+		}
+
+		st, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, st)
+
+		if t := p.peek(); t.kind != tokSeparator && t.kind != tokEOF {
+			return nil, p.errorf(t, "expected end of statement, found %s", t)
+		}
+	}
+}
+
+func (p *parser) statement() (statement, error) {
+	name, err := p.expect(tokIdent, "")
+	if err != nil {
+		return statement{}, err
+	}
+
+	if name.text == "print" && p.peek().kind == tokIdent {
+		target := p.next()
+		return statement{print: true, name: target.text}, nil
+	}
+	if name.text == "print" {
+		return statement{}, p.errorf(p.peek(), "expected variable name after print, found %s", p.peek())
+	}
+
+	if _, err := p.expect(tokOperator, "="); err != nil {
+		return statement{}, err
+	}
+	value, err := p.expr()
+	if err != nil {
+		return statement{}, err
+	}
+	return statement{name: name.text, value: value}, nil
+}
+
+func (p *parser) expr() (node, error) {
+	return p.binary(p.term, "+", "-")
+}
+
+func (p *parser) term() (node, error) {
+	return p.binary(p.unary, "*", "/", "%")
+}
+
+// binary parses a left-associative chain of the given operators.
+func (p *parser) binary(operand func() (node, error), ops ...string) (node, error) {
+	left, err := operand()
+	if err != nil {
+		return node{}, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOperator || !contains(ops, t.text) {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return node{}, err
+		}
+		left = node{op: t.text, args: []node{left, right}}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if t := p.peek(); t.kind == tokOperator && t.text == "-" {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		return negate(operand), nil
+	}
+	return p.power()
+}
+
+func (p *parser) power() (node, error) {
+	base, err := p.primary()
+	if err != nil {
+		return node{}, err
+	}
+	if t := p.peek(); t.kind == tokOperator && t.text == "**" {
+		p.next()
+		exp, err := p.unary()
+		if err != nil {
+			return node{}, err
+		}
+		return node{op: "**", args: []node{base, exp}}, nil
+	}
+	return base, nil
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return node{literal: numberLiteral(t.text)}, nil
+	case tokIdent:
+		if t.text == "print" {
+			return node{}, p.errorf(t, "print cannot be used in an expression")
+		}
+		if p.peek().kind == tokLParen {
+			return p.call(t)
+		}
+		return node{variable: t.text}, nil
+	case tokLParen:
+		inner, err := p.expr()
+		if err != nil {
+			return node{}, err
+		}
+		if _, err := p.expect(tokRParen, ""); err != nil {
+			return node{}, err
+		}
+		return inner, nil
+	}
+	return node{}, p.errorf(t, "expected expression, found %s", t)
+}
+
+func (p *parser) call(name token) (node, error) {
+	p.next()
+	var args []node
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return node{}, err
+		}
+		args = append(args, arg)
+		if p.peek().kind != tokComma {
+			break
+		}
+		p.next()
+	}
+	if _, err := p.expect(tokRParen, ""); err != nil {
+		return node{}, err
+	}
+	if len(args) > 2 {
+		return node{}, p.errorf(name, "%s takes at most 2 arguments, got %d", name.text, len(args))
+	}
+	return node{op: name.text, args: args}, nil
+}
+
+// numberLiteral keeps integers that fit in int64 as int64 and everything
+// else, long integers and decimals, as calc literal strings.
+func numberLiteral(text string) interface{} {
+	if n, err := strconv.ParseInt(text, 10, 64); err == nil {
+		return n
+	}
+	return text
+}
+
+// negate folds unary minus into literals and lowers it as 0 - x otherwise.
+func negate(n node) node {
+	switch lit := n.literal.(type) {
+	case int64:
+		if lit == math.MinInt64 {
+			return node{literal: "9223372036854775808"}
+		}
+		return node{literal: -lit}
+	case string:
+		if lit[0] == '-' {
+			return node{literal: numberLiteral(lit[1:])}
+		}
+		if neg, ok := new(big.Int).SetString("-"+lit, 10); ok && neg.IsInt64() {
+			return node{literal: neg.Int64()}
+		}
+		return node{literal: "-" + lit}
+	}
+	return node{op: "-", args: []node{{literal: int64(0)}, n}}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type lowerer struct {
+	out    []calc.Instruction
+	prefix string
+	temps  int
+}
+
+// assign emits the instructions computing value into name. A bare literal or
+// variable is lowered as name = value + 0.
+func (l *lowerer) assign(name string, value node) {
+	instr := calc.Instruction{Type: "calc", Var: name}
+	if value.op == "" {
+		instr.Op = "+"
+		instr.Left = l.operand(value)
+		instr.Right = int64(0)
+		l.out = append(l.out, instr)
+		return
+	}
+
+	instr.Op = value.op
+	instr.Left = l.operand(value.args[0])
+	if len(value.args) > 1 {
+		instr.Right = l.operand(value.args[1])
+	}
+	l.out = append(l.out, instr)
+}
+
+// operand returns the calc operand for n, emitting a temporary variable for
+// nested operations.
+func (l *lowerer) operand(n node) interface{} {
+	switch {
+	case n.literal != nil:
+		return n.literal
+	case n.variable != "":
+		return n.variable
+	}
+	l.temps++
+	name := fmt.Sprintf("%s%d", l.prefix, l.temps)
+	l.assign(name, n)
+	return name
+}
@@ -0,0 +1,143 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"prac/calc"
+)
+
+func TestParseLowering(t *testing.T) {
+	instructions, err := Parse(`
+		x = 10 + 2
+		z = (x - 3) * y; y = 5
+		print z
+	`)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	expected := []calc.Instruction{
+		{Type: "calc", Op: "+", Var: "x", Left: int64(10), Right: int64(2)},
+		{Type: "calc", Op: "-", Var: ".t1", Left: "x", Right: int64(3)},
+		{Type: "calc", Op: "*", Var: "z", Left: ".t1", Right: "y"},
+		{Type: "calc", Op: "+", Var: "y", Left: int64(5), Right: int64(0)},
+		{Type: "print", Var: "z"},
+	}
+	if !reflect.DeepEqual(instructions, expected) {
+		t.Errorf("unexpected instructions:\n got %+v\nwant %+v", instructions, expected)
+	}
+}
+
+func TestParsePrecedence(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected int64
+	}{
+		{"r = 2 + 3 * 4", 14},
+		{"r = (2 + 3) * 4", 20},
+		{"r = 10 - 4 - 3", 3},
+		{"r = 2 ** 3 ** 2", 512},
+		{"r = -2 ** 2", -4},
+		{"r = 2 ** -0", 1},
+		{"r = 7 % 4 * 2", 6},
+		{"r = -(3 - 5)", 2},
+		{"r = max(1, min(8, 3)) * 2", 6},
+		{"r = -9223372036854775808 + 1", -9223372036854775807},
+		{"r = (1 +\n 2)", 3},
+	}
+
+	calculator := calc.NewCalculator(calc.WithRegistry(zeroCostRegistry()))
+	for _, tt := range tests {
+		instructions, err := Parse(tt.src + "\nprint r")
+		if err != nil {
+			t.Errorf("%q: Parse failed: %v", tt.src, err)
+			continue
+		}
+		results, err := calculator.Calculate(instructions)
+		if err != nil {
+			t.Errorf("%q: Calculate failed: %v", tt.src, err)
+			continue
+		}
+		if len(results) != 1 || results[0].Value != tt.expected {
+			t.Errorf("%q: expected %d, got %v", tt.src, tt.expected, results)
+		}
+	}
+}
+
+func TestParseDecimals(t *testing.T) {
+	instructions, err := Parse("total = 19.99 * 3 // three items\nprint total")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	results, err := calc.NewCalculator(calc.WithRegistry(zeroCostRegistry()), calc.WithDecimal(2, calc.RoundHalfEven)).Calculate(instructions)
+	if err != nil {
+		t.Fatalf("Calculate failed: %v", err)
+	}
+	if len(results) != 1 || results[0].Exact != "59.97" {
+		t.Errorf("expected 59.97, got %v", results)
+	}
+}
+
+func TestParseTempsSession(t *testing.T) {
+	s := calc.NewCalculator(calc.WithRegistry(zeroCostRegistry())).NewSession(context.Background(), calc.RunOptions{Reactive: true})
+	defer s.Close()
+
+	var results []calc.Result
+	for i, src := range []string{"x = (1 + 2) * 3", "y = (10 + 20) * 3; print x; print y"} {
+		instructions, err := ParseTemps(src, fmt.Sprintf(".b%d.t", i+1))
+		if err != nil {
+			t.Fatalf("%q: Parse failed: %v", src, err)
+		}
+		results, err = s.Calculate(context.Background(), instructions)
+		if err != nil {
+			t.Fatalf("%q: Calculate failed: %v", src, err)
+		}
+	}
+	if !reflect.DeepEqual(results, []calc.Result{{Var: "x", Value: 9}, {Var: "y", Value: 90}}) {
+		t.Errorf("expected x = 9 and y = 90, got %v", results)
+	}
+
+	if _, err := ParseTemps("x = (1 + 2) * 3", "t"); err == nil {
+		t.Error("expected error for a prefix without a dot")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src       string
+		line, col int
+	}{
+		{"x = 1 +", 1, 8},
+		{"x = (1 + 2", 1, 11},
+		{"x 1", 1, 3},
+		{"x = 1\ny = 2 3", 2, 7},
+		{"print", 1, 6},
+		{"x = 1 @ 2", 1, 7},
+		{"x = f(1, 2, 3)", 1, 5},
+		{"x = print", 1, 5},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(tt.src)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%q: expected SyntaxError, got %v", tt.src, err)
+			continue
+		}
+		if serr.Line != tt.line || serr.Col != tt.col {
+			t.Errorf("%q: expected error at %d:%d, got %v", tt.src, tt.line, tt.col, serr)
+		}
+	}
+}
+
+func zeroCostRegistry() *calc.OperationRegistry {
+	r := calc.DefaultRegistry()
+	for _, op := range r.Operations() {
+		r.SetLatency(op.Name, calc.ConstantLatency(0))
+	}
+	return r
+}
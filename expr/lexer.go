@@ -0,0 +1,150 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokSeparator
+	tokIdent
+	tokNumber
+	tokOperator
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of input"
+	case tokSeparator:
+		return "end of statement"
+	case tokIdent:
+		return "identifier"
+	case tokNumber:
+		return "number"
+	case tokOperator:
+		return "operator"
+	case tokLParen:
+		return "("
+	case tokRParen:
+		return ")"
+	case tokComma:
+		return ","
+	}
+	return fmt.Sprintf("token(%d)", int(k))
+}
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+	col  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return t.kind.String()
+	case tokSeparator:
+		if t.text == "\n" {
+			return "end of line"
+		}
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lex splits src into tokens. Newlines inside parentheses do not end a
+// statement, so long expressions can be wrapped.
+func lex(src string) ([]token, error) {
+	var tokens []token
+	runes := []rune(src)
+	line, col := 1, 1
+	depth := 0
+
+	emit := func(kind tokenKind, text string, l, c int) {
+		tokens = append(tokens, token{kind: kind, text: text, line: l, col: c})
+	}
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		startLine, startCol := line, col
+		advance := func(n int) {
+			i += n
+			col += n
+		}
+
+		switch {
+		case r == '\n':
+			if depth == 0 {
+				emit(tokSeparator, "\n", startLine, startCol)
+			}
+			i++
+			line++
+			col = 1
+		case r == ';':
+			emit(tokSeparator, ";", startLine, startCol)
+			advance(1)
+		case unicode.IsSpace(r):
+			advance(1)
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			for i < len(runes) && runes[i] != '\n' {
+				advance(1)
+			}
+		case r == '*' && i+1 < len(runes) && runes[i+1] == '*':
+			emit(tokOperator, "**", startLine, startCol)
+			advance(2)
+		case strings.ContainsRune("+-*/%=", r):
+			emit(tokOperator, string(r), startLine, startCol)
+			advance(1)
+		case r == '(':
+			depth++
+			emit(tokLParen, "(", startLine, startCol)
+			advance(1)
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+			emit(tokRParen, ")", startLine, startCol)
+			advance(1)
+		case r == ',':
+			emit(tokComma, ",", startLine, startCol)
+			advance(1)
+		case isDigit(r):
+			j := i
+			for j < len(runes) && isDigit(runes[j]) {
+				j++
+			}
+			if j+1 < len(runes) && runes[j] == '.' && isDigit(runes[j+1]) {
+				j++
+				for j < len(runes) && isDigit(runes[j]) {
+					j++
+				}
+			}
+			emit(tokNumber, string(runes[i:j]), startLine, startCol)
+			advance(j - i)
+		case r == '_' || unicode.IsLetter(r):
+			j := i
+			for j < len(runes) && (runes[j] == '_' || unicode.IsLetter(runes[j]) || isDigit(runes[j])) {
+				j++
+			}
+			emit(tokIdent, string(runes[i:j]), startLine, startCol)
+			advance(j - i)
+		default:
+			return nil, &SyntaxError{Line: startLine, Col: startCol, Msg: fmt.Sprintf("unexpected character %q", r)}
+		}
+	}
+
+	emit(tokEOF, "", line, col)
+	return tokens, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
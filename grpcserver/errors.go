@@ -0,0 +1,72 @@
+package grpcserver
+
+import (
+	"fmt"
+	"prac/calc"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the ErrorInfo domain of the errors of this service.
+const errorDomain = "calculator"
+
+var statusCodes = map[calc.Code]codes.Code{
+	calc.CodeInvalidRequest:     codes.InvalidArgument,
+	calc.CodeInvalidInstruction: codes.InvalidArgument,
+	calc.CodeUndefinedVariable:  codes.InvalidArgument,
+	calc.CodeUnknownOperation:   codes.InvalidArgument,
+	calc.CodeDependencyCycle:    codes.InvalidArgument,
+	calc.CodeDuplicateVariable:  codes.AlreadyExists,
+	calc.CodeOverflow:           codes.OutOfRange,
+	calc.CodeDivisionByZero:     codes.FailedPrecondition,
+	calc.CodeOperationFailed:    codes.FailedPrecondition,
+	calc.CodeTimeout:            codes.DeadlineExceeded,
+	calc.CodeCanceled:           codes.Canceled,
+	calc.CodeSessionClosed:      codes.FailedPrecondition,
+}
+
+// statusError converts an error of the calc package to a status carrying an
+// ErrorInfo with the calc.Code as reason and, for invalid instructions, a
+// BadRequest naming the offending one.
+func statusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	info := calc.Classify(err)
+	code, ok := statusCodes[info.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+	details := &errdetails.ErrorInfo{Reason: string(info.Code), Domain: errorDomain, Metadata: map[string]string{}}
+	if info.Index >= 0 {
+		details.Metadata["index"] = strconv.Itoa(info.Index)
+	}
+	if info.Var != "" {
+		details.Metadata["var"] = info.Var
+	}
+
+	st := status.New(code, err.Error())
+	withDetails, detailsErr := st.WithDetails(details)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	if code == codes.InvalidArgument && info.Index >= 0 {
+		withDetails, detailsErr = withDetails.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       fmt.Sprintf("instructions[%d]", info.Index),
+				Description: err.Error(),
+			}},
+		})
+		if detailsErr != nil {
+			return st.Err()
+		}
+	}
+	return withDetails.Err()
+}
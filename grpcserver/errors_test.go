@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"prac/calc"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err      error
+		code     codes.Code
+		reason   calc.Code
+		metadata map[string]string
+		field    string
+	}{
+		{errors.New("instruction 0: unknown operation: 'jump'"), codes.InvalidArgument, calc.CodeInvalidRequest, map[string]string{}, ""},
+		{&calc.ValidationError{Kind: calc.ArityMismatch, Index: 2, Var: "x", Op: "+", Arity: 2}, codes.InvalidArgument, calc.CodeInvalidInstruction, map[string]string{"index": "2", "var": "x"}, "instructions[2]"},
+		{&calc.ValidationError{Kind: calc.UndefinedReference, Index: 0, Var: "x", Ref: "y"}, codes.InvalidArgument, calc.CodeUndefinedVariable, map[string]string{"index": "0", "var": "x"}, "instructions[0]"},
+		{&calc.ValidationError{Kind: calc.DuplicateVariable, Index: 1, Var: "x"}, codes.AlreadyExists, calc.CodeDuplicateVariable, map[string]string{"index": "1", "var": "x"}, ""},
+		{&calc.ValidationError{Kind: calc.UnknownOperation, Index: 3, Var: "p", Op: "pow"}, codes.InvalidArgument, calc.CodeUnknownOperation, map[string]string{"index": "3", "var": "p"}, "instructions[3]"},
+		{&calc.ValidationError{Kind: calc.SelfReference, Index: 0, Var: "a", Ref: "a"}, codes.InvalidArgument, calc.CodeDependencyCycle, map[string]string{"index": "0", "var": "a"}, "instructions[0]"},
+		{&calc.OperationError{Index: 4, Var: "m", Op: "*", Err: calc.ErrOverflow}, codes.OutOfRange, calc.CodeOverflow, map[string]string{"index": "4", "var": "m"}, ""},
+		{&calc.OperationError{Index: 5, Var: "d", Op: "/", Err: calc.ErrDivisionByZero}, codes.FailedPrecondition, calc.CodeDivisionByZero, map[string]string{"index": "5", "var": "d"}, ""},
+		{&calc.OperationError{Index: 6, Var: "c", Op: "f", Err: errors.New("boom")}, codes.FailedPrecondition, calc.CodeOperationFailed, map[string]string{"index": "6", "var": "c"}, ""},
+		{&calc.IncompleteError{Err: context.DeadlineExceeded, Pending: []string{"x"}}, codes.DeadlineExceeded, calc.CodeTimeout, map[string]string{}, ""},
+		{context.Canceled, codes.Canceled, calc.CodeCanceled, map[string]string{}, ""},
+		{&calc.UndefinedError{Var: "x"}, codes.InvalidArgument, calc.CodeUndefinedVariable, map[string]string{"var": "x"}, ""},
+		{calc.ErrSessionClosed, codes.FailedPrecondition, calc.CodeSessionClosed, map[string]string{}, ""},
+	}
+
+	covered := make(map[calc.Code]bool)
+	for _, tt := range tests {
+		covered[tt.reason] = true
+		st, ok := status.FromError(statusError(tt.err))
+		if !ok || st.Code() != tt.code || st.Message() != tt.err.Error() {
+			t.Errorf("%v: expected %s, got %v", tt.err, tt.code, st)
+			continue
+		}
+
+		var info *errdetails.ErrorInfo
+		var badRequest *errdetails.BadRequest
+		for _, d := range st.Details() {
+			switch d := d.(type) {
+			case *errdetails.ErrorInfo:
+				info = d
+			case *errdetails.BadRequest:
+				badRequest = d
+			default:
+				t.Errorf("%v: unexpected detail %v", tt.err, d)
+			}
+		}
+		if info == nil || info.Reason != string(tt.reason) || info.Domain != errorDomain || len(info.Metadata) != len(tt.metadata) {
+			t.Errorf("%v: unexpected ErrorInfo %v", tt.err, info)
+		} else {
+			for k, v := range tt.metadata {
+				if info.Metadata[k] != v {
+					t.Errorf("%v: expected metadata %s = %s, got %v", tt.err, k, v, info.Metadata)
+				}
+			}
+		}
+		if tt.field == "" {
+			if badRequest != nil {
+				t.Errorf("%v: unexpected BadRequest %v", tt.err, badRequest)
+			}
+		} else if badRequest == nil || len(badRequest.FieldViolations) != 1 ||
+			badRequest.FieldViolations[0].Field != tt.field || badRequest.FieldViolations[0].Description != tt.err.Error() {
+			t.Errorf("%v: expected BadRequest for %s, got %v", tt.err, tt.field, badRequest)
+		}
+	}
+	for code := range statusCodes {
+		if !covered[code] {
+			t.Errorf("code %s is not tested", code)
+		}
+	}
+	if len(statusCodes) != len(covered) {
+		t.Errorf("expected a status code for every code, got %d of %d", len(statusCodes), len(covered))
+	}
+
+	if statusError(nil) != nil {
+		t.Error("expected nil for nil error")
+	}
+	notFound := status.Error(codes.NotFound, "session x not found")
+	if err := statusError(notFound); err != notFound {
+		t.Errorf("expected status errors to pass through, got %v", err)
+	}
+}
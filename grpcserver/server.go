@@ -2,36 +2,351 @@ package grpcserver
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"prac/calc"
 	pb "prac/proto"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type calculatorServer struct {
 	pb.UnimplementedCalculatorServiceServer
 	calcService *calc.Calculator
+	programs    *calc.ProgramStore
+	sessions    *calc.SessionStore
 }
 
-func NewCalculatorServer(calcService *calc.Calculator) *calculatorServer {
-	return &calculatorServer{calcService: calcService}
+func NewCalculatorServer(calcService *calc.Calculator, programs *calc.ProgramStore, sessions *calc.SessionStore) *calculatorServer {
+	return &calculatorServer{calcService: calcService, programs: programs, sessions: sessions}
 }
 
 func (s *calculatorServer) Calculate(ctx context.Context, req *pb.CalculationRequest) (*pb.CalculationResponse, error) {
-	instructions := make([]calc.Instruction, len(req.Instructions))
-	for i, instr := range req.Instructions {
-		instructions[i] = convertProtoInstruction(instr)
+	instructions, err := convertProtoInstructions(req.Instructions)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	report, err := s.calcService.Execute(ctx, instructions, calc.RunOptions{
+		FullEvaluation: req.FullEvaluation,
+		BigInt:         req.BigInt,
+		Trace:          req.Trace,
+		Versioned:      req.Versioned,
+		PrintMode:      calc.PrintMode(req.PrintMode),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &pb.CalculationResponse{
+		Items:  convertToProtoResults(report.Results),
+		Pruned: int32(report.Pruned),
+		Trace:  convertToProtoTrace(report.Trace),
+	}, nil
+}
+
+// CalculateStream sends every print result as soon as it is computed and a
+// summary with all results in print order at the end. If the calculation
+// fails, the stream ends with the error instead of the summary.
+func (s *calculatorServer) CalculateStream(req *pb.CalculationRequest, stream pb.CalculatorService_CalculateStreamServer) error {
+	instructions, err := convertProtoInstructions(req.Instructions)
+	if err != nil {
+		return statusError(err)
+	}
+
+	opts := calc.RunOptions{
+		FullEvaluation: req.FullEvaluation,
+		BigInt:         req.BigInt,
+		Trace:          req.Trace,
+		Versioned:      req.Versioned,
+		PrintMode:      calc.PrintMode(req.PrintMode),
+	}
+	report, err := s.calcService.ExecuteStream(stream.Context(), instructions, opts, func(res calc.Result) error {
+		return stream.Send(&pb.CalculationEvent{
+			Event: &pb.CalculationEvent_Result{Result: convertToProtoResults([]calc.Result{res})[0]},
+		})
+	})
+	if err != nil {
+		return statusError(err)
+	}
+
+	return stream.Send(&pb.CalculationEvent{
+		Event: &pb.CalculationEvent_Summary{Summary: &pb.CalculationSummary{
+			Items:  convertToProtoResults(report.Results),
+			Pruned: int32(report.Pruned),
+			Trace:  convertToProtoTrace(report.Trace),
+		}},
+	})
+}
+
+func (s *calculatorServer) ListOperations(ctx context.Context, req *pb.ListOperationsRequest) (*pb.ListOperationsResponse, error) {
+	ops := s.calcService.Operations()
+	items := make([]*pb.Operation, len(ops))
+	for i, op := range ops {
+		items[i] = &pb.Operation{
+			Name:    op.Name,
+			Arity:   int32(op.Arity),
+			CostMs:  op.Latency.Estimate(op.Arity).Milliseconds(),
+			BigInt:  op.Big != nil,
+			Decimal: op.Decimal != nil,
+		}
+	}
+	return &pb.ListOperationsResponse{Items: items}, nil
+}
+
+func (s *calculatorServer) PrepareProgram(ctx context.Context, req *pb.PrepareProgramRequest) (*pb.PrepareProgramResponse, error) {
+	instructions, err := convertProtoInstructions(req.Instructions)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	program, err := s.calcService.PrepareOptions(instructions, calc.RunOptions{
+		FullEvaluation: req.FullEvaluation,
+		BigInt:         req.BigInt,
+		Versioned:      req.Versioned,
+		PrintMode:      calc.PrintMode(req.PrintMode),
+	})
+	if err != nil {
+		return nil, statusError(err)
 	}
 
-	results, err := s.calcService.Calculate(instructions)
+	id, err := s.programs.Add(program)
 	if err != nil {
-		return nil, err
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.PrepareProgramResponse{
+		Id:         id,
+		Params:     program.Params(),
+		EstimateMs: program.Estimate().Milliseconds(),
+	}, nil
+}
+
+func (s *calculatorServer) RunProgram(ctx context.Context, req *pb.RunProgramRequest) (*pb.CalculationResponse, error) {
+	program, ok := s.programs.Get(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "program %s not found", req.Id)
+	}
+
+	params := make(map[string]interface{}, len(req.Params))
+	for name, value := range req.Params {
+		params[name] = value
+	}
+
+	report, err := program.Run(ctx, params)
+	if err != nil {
+		return nil, statusError(err)
 	}
 
 	return &pb.CalculationResponse{
-		Items: convertToProtoResults(results),
+		Items:  convertToProtoResults(report.Results),
+		Pruned: int32(report.Pruned),
 	}, nil
 }
 
-func convertProtoInstruction(instr *pb.Instruction) calc.Instruction {
+func (s *calculatorServer) DeleteProgram(ctx context.Context, req *pb.DeleteProgramRequest) (*pb.DeleteProgramResponse, error) {
+	if !s.programs.Delete(req.Id) {
+		return nil, status.Errorf(codes.NotFound, "program %s not found", req.Id)
+	}
+	return &pb.DeleteProgramResponse{}, nil
+}
+
+func (s *calculatorServer) Explain(ctx context.Context, req *pb.ExplainRequest) (*pb.ExplainResponse, error) {
+	instructions, err := convertProtoInstructions(req.Instructions)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	program, err := s.calcService.PrepareOptions(instructions, calc.RunOptions{
+		FullEvaluation: req.FullEvaluation,
+		BigInt:         req.BigInt,
+		Versioned:      req.Versioned,
+		PrintMode:      calc.PrintMode(req.PrintMode),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	plan := program.Plan()
+	resp := &pb.ExplainResponse{
+		CriticalPath:   plan.CriticalPath,
+		CriticalPathMs: plan.CriticalPathLength.Milliseconds(),
+		EstimateMs:     plan.Estimate.Milliseconds(),
+		Pruned:         plan.Pruned,
+	}
+	for _, n := range plan.Nodes {
+		resp.Nodes = append(resp.Nodes, &pb.PlanNode{
+			Index:      int32(n.Index),
+			Var:        n.Var,
+			Expr:       n.Expr,
+			Deps:       n.Deps,
+			Level:      int32(n.Level),
+			EstimateMs: n.Estimate.Milliseconds(),
+			Pruned:     n.Pruned,
+		})
+	}
+	for _, level := range plan.Levels {
+		resp.Levels = append(resp.Levels, &pb.PlanLevel{Vars: level})
+	}
+	if req.Format != "" {
+		if resp.Rendering, err = plan.Render(req.Format); err != nil {
+			return nil, statusError(err)
+		}
+	}
+	return resp, nil
+}
+
+// Session evaluates the instructions of the stream as they arrive in one
+// calc.Session. Every print is answered as soon as its value is computed and
+// every rejected instruction with an error event; the stream ends once the
+// client has closed its side and all answers are sent.
+func (s *calculatorServer) Session(stream pb.CalculatorService_SessionServer) error {
+	session := s.calcService.NewSession(stream.Context(), calc.RunOptions{})
+
+	var mu sync.Mutex
+	var sendErr error
+	send := func(event *pb.SessionEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		if sendErr == nil {
+			sendErr = stream.Send(event)
+		}
+	}
+	sendError := func(index int, err error) {
+		send(&pb.SessionEvent{Index: int32(index), Event: &pb.SessionEvent_Error{Error: err.Error()}})
+	}
+
+	var wg sync.WaitGroup
+	var recvErr error
+	for index := 0; ; index++ {
+		msg, err := stream.Recv()
+		if err != nil {
+			if err != io.EOF {
+				recvErr = err
+			}
+			break
+		}
+		instr, err := convertProtoInstruction(msg)
+		if err != nil {
+			sendError(index, err)
+			continue
+		}
+		future, err := session.Submit(instr)
+		if err != nil {
+			sendError(index, err)
+			continue
+		}
+		if future == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			<-future.Done()
+			res, err := future.Result()
+			if err != nil {
+				sendError(index, err)
+				return
+			}
+			send(&pb.SessionEvent{
+				Index: int32(index),
+				Event: &pb.SessionEvent_Result{Result: convertToProtoResults([]calc.Result{res})[0]},
+			})
+		}(index)
+	}
+
+	session.Close()
+	wg.Wait()
+	if recvErr != nil {
+		return recvErr
+	}
+	return sendErr
+}
+
+func (s *calculatorServer) CreateSession(ctx context.Context, req *pb.CreateSessionRequest) (*pb.CreateSessionResponse, error) {
+	id, _, err := s.sessions.Create(calc.RunOptions{BigInt: req.BigInt, Reactive: req.Reactive})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.CreateSessionResponse{Id: id, TtlSeconds: int64(s.sessions.TTL().Seconds())}, nil
+}
+
+func (s *calculatorServer) CalculateInSession(ctx context.Context, req *pb.CalculateInSessionRequest) (*pb.CalculationResponse, error) {
+	session, ok := s.sessions.Get(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.Id)
+	}
+
+	instructions, err := convertProtoInstructions(req.Instructions)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	results, err := session.Calculate(ctx, instructions)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.CalculationResponse{Items: convertToProtoResults(results)}, nil
+}
+
+func (s *calculatorServer) ListSessionVars(ctx context.Context, req *pb.ListSessionVarsRequest) (*pb.ListSessionVarsResponse, error) {
+	session, ok := s.sessions.Get(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.Id)
+	}
+
+	vars := session.Vars()
+	items := make([]*pb.Variable, len(vars))
+	for i, v := range vars {
+		items[i] = convertToProtoVariable(v)
+	}
+	return &pb.ListSessionVarsResponse{Items: items}, nil
+}
+
+// WatchSession streams the state of the requested variables every time one
+// is computed or fails, until the client cancels or the session is closed.
+func (s *calculatorServer) WatchSession(req *pb.WatchSessionRequest, stream pb.CalculatorService_WatchSessionServer) error {
+	session, ok := s.sessions.Get(req.Id)
+	if !ok {
+		return status.Errorf(codes.NotFound, "session %s not found", req.Id)
+	}
+
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+	var sendErr error
+	err := session.Subscribe(ctx, req.Vars, func(v calc.Variable) {
+		if sendErr = stream.Send(convertToProtoVariable(v)); sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	return statusError(err)
+}
+
+func (s *calculatorServer) DeleteSession(ctx context.Context, req *pb.DeleteSessionRequest) (*pb.DeleteSessionResponse, error) {
+	if !s.sessions.Delete(req.Id) {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", req.Id)
+	}
+	return &pb.DeleteSessionResponse{}, nil
+}
+
+func convertProtoInstructions(instrs []*pb.Instruction) ([]calc.Instruction, error) {
+	instructions := make([]calc.Instruction, len(instrs))
+	for i, instr := range instrs {
+		converted, err := convertProtoInstruction(instr)
+		if err != nil {
+			return nil, fmt.Errorf("instruction %d: %w", i, err)
+		}
+		instructions[i] = converted
+	}
+	return instructions, nil
+}
+
+func convertProtoInstruction(instr *pb.Instruction) (calc.Instruction, error) {
 	res := calc.Instruction{
 		Type: instr.Type,
 		Op:   instr.Op,
@@ -43,6 +358,11 @@ func convertProtoInstruction(instr *pb.Instruction) calc.Instruction {
 		res.Left = x.LeftInt
 	case *pb.Instruction_LeftVar:
 		res.Left = x.LeftVar
+	case *pb.Instruction_LeftLiteral:
+		if !calc.IsLiteral(x.LeftLiteral) {
+			return res, fmt.Errorf("invalid left literal %q", x.LeftLiteral)
+		}
+		res.Left = x.LeftLiteral
 	}
 
 	switch x := instr.Right.(type) {
@@ -50,18 +370,57 @@ func convertProtoInstruction(instr *pb.Instruction) calc.Instruction {
 		res.Right = x.RightInt
 	case *pb.Instruction_RightVar:
 		res.Right = x.RightVar
+	case *pb.Instruction_RightLiteral:
+		if !calc.IsLiteral(x.RightLiteral) {
+			return res, fmt.Errorf("invalid right literal %q", x.RightLiteral)
+		}
+		res.Right = x.RightLiteral
 	}
 
-	return res
+	return res, nil
 }
 
 func convertToProtoResults(results []calc.Result) []*pb.Result {
 	protoResults := make([]*pb.Result, len(results))
 	for i, res := range results {
 		protoResults[i] = &pb.Result{
-			Var:   res.Var,
-			Value: res.Value,
+			Var:     res.Var,
+			Value:   res.Value,
+			Exact:   res.Exact,
+			Version: int32(res.Version),
+			Missing: res.Missing,
+			Error:   res.Error,
 		}
 	}
 	return protoResults
 }
+
+func convertToProtoTrace(spans []calc.Span) []*pb.Span {
+	protoSpans := make([]*pb.Span, len(spans))
+	for i, s := range spans {
+		waits := make([]*pb.DependencyWait, len(s.Waits))
+		for j, w := range s.Waits {
+			waits[j] = &pb.DependencyWait{Dep: w.Dep, WaitUs: w.Duration.Microseconds()}
+		}
+		protoSpans[i] = &pb.Span{
+			Index:         int32(s.Index),
+			Var:           s.Var,
+			Op:            s.Op,
+			Status:        string(s.Status),
+			Worker:        int32(s.Worker),
+			StartUnixNano: s.Start.UnixNano(),
+			EndUnixNano:   s.End.UnixNano(),
+			QueuedUs:      s.Queued.Microseconds(),
+			Waits:         waits,
+		}
+	}
+	return protoSpans
+}
+
+func convertToProtoVariable(v calc.Variable) *pb.Variable {
+	res := &pb.Variable{Var: v.Var, Value: v.Value, Exact: v.Exact, Status: string(v.Status)}
+	if v.Err != nil {
+		res.Error = v.Err.Error()
+	}
+	return res
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"mime"
 	"net"
 	"net/http"
+	"os"
 	"prac/calc"
+	"prac/expr"
 	"prac/grpcserver"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	pb "prac/proto"
 
@@ -19,7 +29,108 @@ import (
 )
 
 type ResponseWrapper struct {
-	Items []calc.Result `json:"items"`
+	Items  []calc.Result `json:"items"`
+	Pruned int           `json:"pruned"`
+	Trace  []SpanInfo    `json:"trace,omitempty"`
+}
+
+// StreamEvent is a line of an NDJSON response: a print result as soon as
+// it is computed, then a summary once the calculation has finished.
+type StreamEvent struct {
+	Result  *calc.Result   `json:"result,omitempty"`
+	Summary *StreamSummary `json:"summary,omitempty"`
+}
+
+type StreamSummary struct {
+	ResponseWrapper
+	Error string `json:"error,omitempty"`
+}
+
+type SpanInfo struct {
+	Index    int        `json:"index"`
+	Var      string     `json:"var"`
+	Op       string     `json:"op"`
+	Status   string     `json:"status"`
+	Worker   int        `json:"worker"`
+	Start    time.Time  `json:"start"`
+	End      time.Time  `json:"end"`
+	QueuedUs int64      `json:"queued_us"`
+	Waits    []WaitInfo `json:"waits"`
+}
+
+type WaitInfo struct {
+	Dep    string `json:"dep"`
+	WaitUs int64  `json:"wait_us"`
+}
+
+type OperationInfo struct {
+	Name    string `json:"name"`
+	Arity   int    `json:"arity"`
+	CostMs  int64  `json:"cost_ms"`
+	BigInt  bool   `json:"big_int"`
+	Decimal bool   `json:"decimal"`
+}
+
+type OperationsWrapper struct {
+	Items []OperationInfo `json:"items"`
+}
+
+type PlanNodeInfo struct {
+	Index      int      `json:"index"`
+	Var        string   `json:"var"`
+	Expr       string   `json:"expr"`
+	Deps       []string `json:"deps"`
+	Level      int      `json:"level"`
+	EstimateMs int64    `json:"estimate_ms"`
+	Pruned     bool     `json:"pruned"`
+}
+
+type PlanResponse struct {
+	Nodes          []PlanNodeInfo `json:"nodes"`
+	Levels         [][]string     `json:"levels"`
+	CriticalPath   []string       `json:"critical_path"`
+	CriticalPathMs int64          `json:"critical_path_ms"`
+	EstimateMs     int64          `json:"estimate_ms"`
+	Pruned         []string       `json:"pruned"`
+	Rendering      string         `json:"rendering,omitempty"`
+}
+
+type ProgramInfo struct {
+	ID         string   `json:"id"`
+	Params     []string `json:"params"`
+	EstimateMs int64    `json:"estimate_ms"`
+}
+
+type SessionInfo struct {
+	ID         string `json:"id"`
+	TTLSeconds int64  `json:"ttl_seconds"`
+}
+
+// VariableInfo is a variable of a session. value and exact are only set
+// once its status is ok.
+type VariableInfo struct {
+	Var    string `json:"var"`
+	Value  int64  `json:"value"`
+	Exact  string `json:"exact,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type VariablesWrapper struct {
+	Items []VariableInfo `json:"items"`
+}
+
+// Problem is an RFC 7807 problem details object. Code is the calc.Code of
+// the error, Index and Var name the offending instruction, if any.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	Index    *int   `json:"index,omitempty"`
+	Var      string `json:"var,omitempty"`
 }
 
 // @title Calculator API
@@ -28,32 +139,96 @@ type ResponseWrapper struct {
 // @host localhost:8080
 // @BasePath /
 func main() {
+	decimalScale := flag.Int("decimal-scale", calc.DefaultDecimalConfig.Scale, "number of fractional digits of decimal values")
+	decimalRounding := flag.String("decimal-rounding", calc.DefaultDecimalConfig.Rounding.String(), "rounding of decimal * and /: half-even, half-up, down, floor or ceiling")
+	latencyConfig := flag.String("latency-config", "", "JSON file with latency models of operations")
+	workers := flag.Int("workers", calc.DefaultWorkers(), "maximum number of instructions a calculation computes at once, 0 for no limit")
+	sessionTTL := flag.Duration("session-ttl", 10*time.Minute, "time after its last use at which a session expires")
+	programTTL := flag.Duration("program-ttl", time.Hour, "time after its last use at which a program expires")
+	flag.Parse()
+
+	rounding, err := calc.ParseRoundingMode(*decimalRounding)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry := calc.DefaultRegistry()
+	if *latencyConfig != "" {
+		f, err := os.Open(*latencyConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = calc.LoadLatencyConfig(f, registry)
+		f.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", *latencyConfig, err)
+		}
+	}
+	calculator := calc.NewCalculator(
+		calc.WithRegistry(registry),
+		calc.WithDecimal(*decimalScale, rounding),
+		calc.WithWorkers(*workers),
+	)
+	programs := calc.NewProgramStore(calculator, *programTTL)
+	sessions := calc.NewSessionStore(calculator, *sessionTTL)
+	go func() {
+		for range time.Tick(sweepInterval(*sessionTTL)) {
+			sessions.Expire()
+		}
+	}()
+	go func() {
+		for range time.Tick(sweepInterval(*programTTL)) {
+			programs.Expire()
+		}
+	}()
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 
 	go func() {
 		defer wg.Done()
-		startHTTPServer()
+		startHTTPServer(calculator, programs, sessions)
 	}()
 
 	go func() {
 		defer wg.Done()
-		startGRPCServer()
+		startGRPCServer(calculator, programs, sessions)
 	}()
 
 	wg.Wait()
 }
 
+// sweepInterval is the period of the expiry sweep of a store with the given
+// TTL, short enough that nothing outlives its TTL by more than a quarter.
+func sweepInterval(ttl time.Duration) time.Duration {
+	return max(ttl/4, time.Second)
+}
+
 // Calculate godoc
 // @Summary Calculate operations
-// @Description Perform a batch of calculations with 50ms delay per operation
+// @Description Perform a batch of calculations. Every operation takes its simulated latency, 50ms by default.
+// @Description Operands are integers, variable names or decimal strings such as "12.345".
+// @Description With Content-Type text/plain the body is a program in the infix language, e.g. "x = 1 + 2; print x".
+// @Description With Accept application/x-ndjson or text/event-stream every print result is sent as soon as it is computed,
+// @Description as {"result": ...} lines or result events, followed by a summary with all results and an error, if any.
+// @Description Errors are otherwise returned as application/problem+json with the calc error code and the offending instruction.
 // @Tags Calculator
 // @Accept json
+// @Accept plain
 // @Produce json
+// @Produce application/x-ndjson
+// @Produce text/event-stream
 // @Param instructions body []calc.Instruction true "Array of calculation instructions"
+// @Param full query bool false "Evaluate every instruction, including those no print depends on"
+// @Param big query bool false "Compute with arbitrary-precision integers; exact values are returned in the exact field"
+// @Param versioned query bool false "Allow assigning a variable several times, each assignment defining a new version"
+// @Param print query string false "Value reported by a print: final, the default, or program for only variables defined before the print" Enums(final, program)
+// @Param trace query bool false "Include the execution trace of every calc instruction"
+// @Param timeout query string false "Maximum duration of the calculation, e.g. 500ms"
 // @Success 200 {object} ResponseWrapper
-// @Failure 400 {string} string "Invalid request format"
-// @Failure 500 {string} string "Internal calculation error"
+// @Failure 400 {object} Problem "Invalid request format"
+// @Failure 409 {object} Problem "Variable assigned twice"
+// @Failure 422 {object} Problem "Invalid instruction or failed operation"
+// @Failure 504 {object} Problem "Timeout exceeded"
 // @Router /calculate [post]
 // @Example request
 // [
@@ -67,27 +242,52 @@ func main() {
 //	{
 //	  "items": [
 //	    { "var": "x", "value": 3 }
-//	  ]
+//	  ],
+//	  "pruned": 0
 //	}
-func startHTTPServer() {
+func startHTTPServer(calculator *calc.Calculator, programs *calc.ProgramStore, sessions *calc.SessionStore) {
 	http.HandleFunc("/calculate", func(w http.ResponseWriter, r *http.Request) {
-		var instructions []calc.Instruction
-		calculator := calc.NewCalculator()
-		if err := json.NewDecoder(r.Body).Decode(&instructions); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+		r, cancel, err := withTimeout(r)
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
+		defer cancel()
 
-		results, err := calculator.Calculate(instructions)
+		instructions, err := decodeInstructions(r)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, err)
+			return
+		}
+
+		if format := streamFormat(r); format != "" {
+			streamCalculation(w, r, calculator, instructions, format)
+			return
+		}
+
+		report, err := calculator.Execute(r.Context(), instructions, runOptions(r))
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(ResponseWrapper{Items: results})
+		json.NewEncoder(w).Encode(ResponseWrapper{Items: report.Results, Pruned: report.Pruned, Trace: spanInfos(report.Trace)})
 	})
 
+	http.HandleFunc("POST /calculate/trace", traceHandler(calculator))
+
+	http.HandleFunc("POST /calculate/plan", planHandler(calculator))
+	http.HandleFunc("/operations", operationsHandler(calculator))
+	http.HandleFunc("POST /programs", prepareProgramHandler(calculator, programs))
+	http.HandleFunc("POST /programs/{id}/run", runProgramHandler(programs))
+	http.HandleFunc("DELETE /programs/{id}", deleteProgramHandler(programs))
+	http.HandleFunc("POST /sessions", createSessionHandler(sessions))
+	http.HandleFunc("POST /sessions/{id}/calculate", sessionCalculateHandler(sessions))
+	http.HandleFunc("GET /sessions/{id}/vars", sessionVarsHandler(sessions))
+	http.HandleFunc("GET /sessions/{id}/watch", watchSessionHandler(sessions))
+	http.HandleFunc("DELETE /sessions/{id}", deleteSessionHandler(sessions))
+
 	http.HandleFunc("/swagger/", httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"),
 	))
@@ -96,15 +296,612 @@ func startHTTPServer() {
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
 
-func startGRPCServer() {
-	calculator := calc.NewCalculator()
+// decodeInstructions reads a JSON instruction list or, for text/plain
+// bodies, compiles an infix program.
+func decodeInstructions(r *http.Request) ([]calc.Instruction, error) {
+	return decodeInstructionsTemps(r, ".t")
+}
+
+// decodeInstructionsTemps is like decodeInstructions but names the temporary
+// variables of an infix program with prefix.
+func decodeInstructionsTemps(r *http.Request, prefix string) ([]calc.Instruction, error) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "text/plain" {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		return expr.ParseTemps(string(body), prefix)
+	}
+
+	var instructions []calc.Instruction
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&instructions); err != nil {
+		return nil, err
+	}
+	return instructions, nil
+}
+
+// streamFormat returns the streaming media type the client accepts, if any.
+func streamFormat(r *http.Request) string {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := mime.ParseMediaType(strings.TrimSpace(accept))
+		if mediaType == "application/x-ndjson" || mediaType == "text/event-stream" {
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// streamCalculation writes every print result as soon as it is computed and
+// a summary at the end, as NDJSON lines or as server-sent events named
+// result and summary. Errors are reported in the summary since the status
+// code has already been sent. A failed write cancels the calculation.
+func streamCalculation(w http.ResponseWriter, r *http.Request, calculator *calc.Calculator, instructions []calc.Instruction, format string) {
+	rc := http.NewResponseController(w)
+	write := func(event StreamEvent) error {
+		var err error
+		if format == "text/event-stream" {
+			name, data := "result", interface{}(event.Result)
+			if event.Summary != nil {
+				name, data = "summary", event.Summary
+			}
+			payload, _ := json.Marshal(data)
+			_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+		} else {
+			err = json.NewEncoder(w).Encode(event)
+		}
+		if err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	w.Header().Set("Content-Type", format)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	report, err := calculator.ExecuteStream(r.Context(), instructions, runOptions(r), func(res calc.Result) error {
+		return write(StreamEvent{Result: &res})
+	})
+	summary := &StreamSummary{ResponseWrapper: ResponseWrapper{Items: []calc.Result{}}}
+	if report != nil {
+		summary.Items = append(summary.Items, report.Results...)
+		summary.Pruned = report.Pruned
+		summary.Trace = spanInfos(report.Trace)
+	}
+	if err != nil {
+		summary.Error = err.Error()
+	}
+	write(StreamEvent{Summary: summary})
+}
+
+func runOptions(r *http.Request) calc.RunOptions {
+	full, _ := strconv.ParseBool(r.URL.Query().Get("full"))
+	bigInt, _ := strconv.ParseBool(r.URL.Query().Get("big"))
+	trace, _ := strconv.ParseBool(r.URL.Query().Get("trace"))
+	versioned, _ := strconv.ParseBool(r.URL.Query().Get("versioned"))
+	reactive, _ := strconv.ParseBool(r.URL.Query().Get("reactive"))
+	return calc.RunOptions{
+		FullEvaluation: full,
+		BigInt:         bigInt,
+		Trace:          trace,
+		PrintMode:      calc.PrintMode(r.URL.Query().Get("print")),
+		Versioned:      versioned,
+		Reactive:       reactive,
+	}
+}
+
+// withTimeout bounds the context of r by its timeout query parameter, if
+// given.
+func withTimeout(r *http.Request) (*http.Request, context.CancelFunc, error) {
+	timeout := r.URL.Query().Get("timeout")
+	if timeout == "" {
+		return r, func() {}, nil
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return r, nil, fmt.Errorf("invalid timeout %q", timeout)
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), d)
+	return r.WithContext(ctx), cancel, nil
+}
+
+// problemStatus is the HTTP status of each calc.Code; the codes of malformed
+// requests are 400, those of valid requests the calculator cannot carry out
+// are 409, 422, 504 or 499 when the client went away.
+var problemStatus = map[calc.Code]int{
+	calc.CodeInvalidRequest:     http.StatusBadRequest,
+	calc.CodeInvalidInstruction: http.StatusUnprocessableEntity,
+	calc.CodeUndefinedVariable:  http.StatusUnprocessableEntity,
+	calc.CodeUnknownOperation:   http.StatusUnprocessableEntity,
+	calc.CodeDependencyCycle:    http.StatusUnprocessableEntity,
+	calc.CodeDuplicateVariable:  http.StatusConflict,
+	calc.CodeOverflow:           http.StatusUnprocessableEntity,
+	calc.CodeDivisionByZero:     http.StatusUnprocessableEntity,
+	calc.CodeOperationFailed:    http.StatusUnprocessableEntity,
+	calc.CodeTimeout:            http.StatusGatewayTimeout,
+	calc.CodeCanceled:           499,
+	calc.CodeSessionClosed:      http.StatusGone,
+}
+
+func newProblem(r *http.Request, status int, code, detail string) Problem {
+	title := strings.ReplaceAll(code, "_", " ")
+	return Problem{
+		Type:     "urn:problem-type:calculator:" + code,
+		Title:    strings.ToUpper(title[:1]) + title[1:],
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	}
+}
+
+// writeError writes err as a problem with the status of its calc.Code.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	info := calc.Classify(err)
+	status, ok := problemStatus[info.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	p := newProblem(r, status, string(info.Code), err.Error())
+	if info.Index >= 0 {
+		p.Index = &info.Index
+	}
+	p.Var = info.Var
+	writeProblem(w, p)
+}
+
+func writeProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+func spanInfos(spans []calc.Span) []SpanInfo {
+	if spans == nil {
+		return nil
+	}
+	infos := make([]SpanInfo, len(spans))
+	for i, s := range spans {
+		waits := make([]WaitInfo, len(s.Waits))
+		for j, w := range s.Waits {
+			waits[j] = WaitInfo{Dep: w.Dep, WaitUs: w.Duration.Microseconds()}
+		}
+		infos[i] = SpanInfo{
+			Index:    s.Index,
+			Var:      s.Var,
+			Op:       s.Op,
+			Status:   string(s.Status),
+			Worker:   s.Worker,
+			Start:    s.Start,
+			End:      s.End,
+			QueuedUs: s.Queued.Microseconds(),
+			Waits:    waits,
+		}
+	}
+	return infos
+}
+
+// Trace godoc
+// @Summary Trace a batch
+// @Description Run a batch and return its execution trace in the Chrome trace event format, which chrome://tracing and Perfetto can load.
+// @Description The trace is returned also when an instruction fails; the status of every span tells which.
+// @Tags Calculator
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param instructions body []calc.Instruction true "Array of calculation instructions"
+// @Param full query bool false "Evaluate every instruction, including those no print depends on"
+// @Param big query bool false "Compute with arbitrary-precision integers"
+// @Param versioned query bool false "Allow assigning a variable several times, each assignment defining a new version"
+// @Param print query string false "Value reported by a print: final, the default, or program for only variables defined before the print" Enums(final, program)
+// @Success 200 {object} calc.ChromeTrace
+// @Failure 400 {object} Problem "Invalid request format"
+// @Failure 409 {object} Problem "Variable assigned twice"
+// @Failure 422 {object} Problem "Invalid instruction"
+// @Router /calculate/trace [post]
+func traceHandler(calculator *calc.Calculator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instructions, err := decodeInstructions(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		opts := runOptions(r)
+		opts.Trace = true
+		report, err := calculator.Execute(r.Context(), instructions, opts)
+		if report == nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(calc.NewChromeTrace(report))
+	}
+}
+
+// Plan godoc
+// @Summary Explain a batch
+// @Description Return the dependency graph of a batch without running it: the levels of instructions that run concurrently, the critical path and the pruned instructions.
+// @Description With format=dot or format=mermaid the graph is also rendered for Graphviz or Mermaid.
+// @Tags Calculator
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param instructions body []calc.Instruction true "Array of calculation instructions"
+// @Param full query bool false "Evaluate every instruction, including those no print depends on"
+// @Param big query bool false "Compute with arbitrary-precision integers"
+// @Param versioned query bool false "Allow assigning a variable several times, each assignment defining a new version"
+// @Param print query string false "Value reported by a print: final, the default, or program for only variables defined before the print" Enums(final, program)
+// @Param format query string false "Rendering of the graph" Enums(dot, mermaid)
+// @Success 200 {object} PlanResponse
+// @Failure 400 {object} Problem "Invalid request format"
+// @Failure 409 {object} Problem "Variable assigned twice"
+// @Failure 422 {object} Problem "Invalid instruction"
+// @Router /calculate/plan [post]
+func planHandler(calculator *calc.Calculator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instructions, err := decodeInstructions(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		program, err := calculator.PrepareOptions(instructions, runOptions(r))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		plan := program.Plan()
+		resp := PlanResponse{
+			Nodes:          make([]PlanNodeInfo, len(plan.Nodes)),
+			Levels:         plan.Levels,
+			CriticalPath:   plan.CriticalPath,
+			CriticalPathMs: plan.CriticalPathLength.Milliseconds(),
+			EstimateMs:     plan.Estimate.Milliseconds(),
+			Pruned:         plan.Pruned,
+		}
+		for i, n := range plan.Nodes {
+			resp.Nodes[i] = PlanNodeInfo{
+				Index:      n.Index,
+				Var:        n.Var,
+				Expr:       n.Expr,
+				Deps:       n.Deps,
+				Level:      n.Level,
+				EstimateMs: n.Estimate.Milliseconds(),
+				Pruned:     n.Pruned,
+			}
+		}
+		if format := r.URL.Query().Get("format"); format != "" {
+			if resp.Rendering, err = plan.Render(format); err != nil {
+				writeError(w, r, err)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// PrepareProgram godoc
+// @Summary Register a program
+// @Description Validate and plan a batch once and store it for repeated runs.
+// @Description Operands of the form "$name" are parameters bound on every run.
+// @Description estimate_ms predicts the duration of a run from the latency models of the operations.
+// @Description The program expires when it has not been run for the -program-ttl of the server.
+// @Tags Programs
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param instructions body []calc.Instruction true "Array of calculation instructions"
+// @Param full query bool false "Evaluate every instruction, including those no print depends on"
+// @Param big query bool false "Compute with arbitrary-precision integers"
+// @Param versioned query bool false "Allow assigning a variable several times, each assignment defining a new version"
+// @Param print query string false "Value reported by a print: final, the default, or program for only variables defined before the print" Enums(final, program)
+// @Success 201 {object} ProgramInfo
+// @Failure 400 {object} Problem "Invalid request format"
+// @Failure 409 {object} Problem "Variable assigned twice"
+// @Failure 422 {object} Problem "Invalid instruction"
+// @Failure 500 {object} Problem "Internal error"
+// @Router /programs [post]
+func prepareProgramHandler(calculator *calc.Calculator, programs *calc.ProgramStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		instructions, err := decodeInstructions(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		program, err := calculator.PrepareOptions(instructions, runOptions(r))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		id, err := programs.Add(program)
+		if err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, "internal", err.Error()))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(ProgramInfo{
+			ID:         id,
+			Params:     program.Params(),
+			EstimateMs: program.Estimate().Milliseconds(),
+		})
+	}
+}
+
+// RunProgram godoc
+// @Summary Run a program
+// @Description Run a registered program with the given parameter values, keyed by name without the "$".
+// @Tags Programs
+// @Accept json
+// @Produce json
+// @Param id path string true "Program ID"
+// @Param params body map[string]interface{} false "Parameter values"
+// @Param timeout query string false "Maximum duration of the run, e.g. 500ms"
+// @Success 200 {object} ResponseWrapper
+// @Failure 400 {object} Problem "Invalid parameters"
+// @Failure 404 {object} Problem "Program not found"
+// @Failure 422 {object} Problem "Failed operation"
+// @Failure 504 {object} Problem "Timeout exceeded"
+// @Router /programs/{id}/run [post]
+func runProgramHandler(programs *calc.ProgramStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		program, ok := programs.Get(r.PathValue("id"))
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "program not found"))
+			return
+		}
+		r, cancel, err := withTimeout(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		defer cancel()
+
+		var params map[string]interface{}
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&params); err != nil && err != io.EOF {
+			writeError(w, r, err)
+			return
+		}
+
+		report, err := program.Run(r.Context(), params)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ResponseWrapper{Items: report.Results, Pruned: report.Pruned})
+	}
+}
+
+// DeleteProgram godoc
+// @Summary Delete a program
+// @Tags Programs
+// @Param id path string true "Program ID"
+// @Success 204
+// @Failure 404 {object} Problem "Program not found"
+// @Router /programs/{id} [delete]
+func deleteProgramHandler(programs *calc.ProgramStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !programs.Delete(r.PathValue("id")) {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "program not found"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// CreateSession godoc
+// @Summary Create a session
+// @Description Create a variable namespace that lives across requests. Variables are write-once within a session.
+// @Description The session expires ttl_seconds after its last use.
+// @Description In a reactive session calcs may redefine variables, and the variables depending on them are recomputed.
+// @Tags Sessions
+// @Produce json
+// @Param big query bool false "Compute with arbitrary-precision integers"
+// @Param reactive query bool false "Allow redefining variables"
+// @Success 201 {object} SessionInfo
+// @Failure 500 {object} Problem "Internal error"
+// @Router /sessions [post]
+func createSessionHandler(sessions *calc.SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, _, err := sessions.Create(runOptions(r))
+		if err != nil {
+			writeProblem(w, newProblem(r, http.StatusInternalServerError, "internal", err.Error()))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(SessionInfo{ID: id, TTLSeconds: int64(sessions.TTL().Seconds())})
+	}
+}
+
+// SessionCalculate godoc
+// @Summary Calculate in a session
+// @Description Run a batch in a session. Its instructions may reference the variables defined by earlier batches of the session.
+// @Description A rejected batch defines no variable.
+// @Tags Sessions
+// @Accept json
+// @Accept plain
+// @Produce json
+// @Param id path string true "Session ID"
+// @Param instructions body []calc.Instruction true "Array of calculation instructions"
+// @Param timeout query string false "Maximum time to wait for the prints, e.g. 500ms"
+// @Success 200 {object} ResponseWrapper
+// @Failure 400 {object} Problem "Invalid request format"
+// @Failure 404 {object} Problem "Session not found"
+// @Failure 409 {object} Problem "Variable already defined"
+// @Failure 422 {object} Problem "Invalid instruction or failed operation"
+// @Failure 504 {object} Problem "Timeout exceeded"
+// @Router /sessions/{id}/calculate [post]
+func sessionCalculateHandler(sessions *calc.SessionStore) http.HandlerFunc {
+	// Batches share the variables of their session, so each infix batch
+	// names its temporaries apart from those of the others.
+	var batches atomic.Int64
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "session not found"))
+			return
+		}
+		r, cancel, err := withTimeout(r)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		defer cancel()
+
+		instructions, err := decodeInstructionsTemps(r, fmt.Sprintf(".b%d.t", batches.Add(1)))
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		results, err := session.Calculate(r.Context(), instructions)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ResponseWrapper{Items: results})
+	}
+}
+
+// SessionVars godoc
+// @Summary List session variables
+// @Description List the variables of a session in definition order with their status and, once computed, value.
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} VariablesWrapper
+// @Failure 404 {object} Problem "Session not found"
+// @Router /sessions/{id}/vars [get]
+func sessionVarsHandler(sessions *calc.SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "session not found"))
+			return
+		}
+
+		vars := session.Vars()
+		items := make([]VariableInfo, len(vars))
+		for i, v := range vars {
+			items[i] = variableInfo(v)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(VariablesWrapper{Items: items})
+	}
+}
+
+// WatchSession godoc
+// @Summary Watch session variables
+// @Description Stream the state of session variables as server-sent change events: first of those already computed,
+// @Description then every time one is computed or fails. In a reactive session this includes every recomputation
+// @Description after a redefinition. The stream ends when the session is deleted or expires.
+// @Tags Sessions
+// @Produce text/event-stream
+// @Param id path string true "Session ID"
+// @Param var query []string false "Variables to watch, all if omitted" collectionFormat(multi)
+// @Success 200 {object} VariableInfo
+// @Failure 404 {object} Problem "Session not found"
+// @Router /sessions/{id}/watch [get]
+func watchSessionHandler(sessions *calc.SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session, ok := sessions.Get(r.PathValue("id"))
+		if !ok {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "session not found"))
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		rc.Flush()
+
+		session.Subscribe(r.Context(), r.URL.Query()["var"], func(v calc.Variable) {
+			payload, _ := json.Marshal(variableInfo(v))
+			fmt.Fprintf(w, "event: change\ndata: %s\n\n", payload)
+			rc.Flush()
+		})
+	}
+}
+
+func variableInfo(v calc.Variable) VariableInfo {
+	info := VariableInfo{Var: v.Var, Value: v.Value, Exact: v.Exact, Status: string(v.Status)}
+	if v.Err != nil {
+		info.Error = v.Err.Error()
+	}
+	return info
+}
+
+// DeleteSession godoc
+// @Summary Delete a session
+// @Description Delete a session and its variables, canceling its running operations.
+// @Tags Sessions
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 404 {object} Problem "Session not found"
+// @Router /sessions/{id} [delete]
+func deleteSessionHandler(sessions *calc.SessionStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !sessions.Delete(r.PathValue("id")) {
+			writeProblem(w, newProblem(r, http.StatusNotFound, "not_found", "session not found"))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// Operations godoc
+// @Summary List operations
+// @Description List the operations accepted in calc instructions with their estimated latency
+// @Tags Calculator
+// @Produce json
+// @Success 200 {object} OperationsWrapper
+// @Router /operations [get]
+func operationsHandler(calculator *calc.Calculator) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeProblem(w, newProblem(r, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed"))
+			return
+		}
+
+		ops := calculator.Operations()
+		items := make([]OperationInfo, len(ops))
+		for i, op := range ops {
+			items[i] = OperationInfo{Name: op.Name, Arity: op.Arity, CostMs: op.Latency.Estimate(op.Arity).Milliseconds(), BigInt: op.Big != nil, Decimal: op.Decimal != nil}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(OperationsWrapper{Items: items})
+	}
+}
+
+func startGRPCServer(calculator *calc.Calculator, programs *calc.ProgramStore, sessions *calc.SessionStore) {
 	lis, err := net.Listen("tcp", ":9090")
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
 
 	grpcServer := grpc.NewServer()
-	pb.RegisterCalculatorServiceServer(grpcServer, grpcserver.NewCalculatorServer(calculator))
+	pb.RegisterCalculatorServiceServer(grpcServer, grpcserver.NewCalculatorServer(calculator, programs, sessions))
 
 	fmt.Println("gRPC server started at :9090")
 	log.Fatal(grpcServer.Serve(lis))
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"prac/calc"
+	"testing"
+)
+
+func TestWriteError(t *testing.T) {
+	index := func(i int) *int { return &i }
+	tests := []struct {
+		err    error
+		status int
+		code   calc.Code
+		index  *int
+		v      string
+	}{
+		{errors.New("unexpected EOF"), http.StatusBadRequest, calc.CodeInvalidRequest, nil, ""},
+		{&calc.ValidationError{Kind: calc.ArityMismatch, Index: 2, Var: "x", Op: "+", Arity: 2}, http.StatusUnprocessableEntity, calc.CodeInvalidInstruction, index(2), "x"},
+		{&calc.ValidationError{Kind: calc.UndefinedReference, Index: 0, Var: "x", Ref: "y"}, http.StatusUnprocessableEntity, calc.CodeUndefinedVariable, index(0), "x"},
+		{&calc.ValidationError{Kind: calc.DuplicateVariable, Index: 1, Var: "x"}, http.StatusConflict, calc.CodeDuplicateVariable, index(1), "x"},
+		{&calc.ValidationError{Kind: calc.UnknownOperation, Index: 3, Var: "p", Op: "pow"}, http.StatusUnprocessableEntity, calc.CodeUnknownOperation, index(3), "p"},
+		{&calc.ValidationError{Kind: calc.DependencyCycle, Index: 0, Var: "a", Cycle: []string{"a", "b", "a"}}, http.StatusUnprocessableEntity, calc.CodeDependencyCycle, index(0), "a"},
+		{&calc.OperationError{Index: 4, Var: "m", Op: "*", Err: calc.ErrOverflow}, http.StatusUnprocessableEntity, calc.CodeOverflow, index(4), "m"},
+		{&calc.OperationError{Index: 5, Var: "d", Op: "/", Err: calc.ErrDivisionByZero}, http.StatusUnprocessableEntity, calc.CodeDivisionByZero, index(5), "d"},
+		{&calc.OperationError{Index: 6, Var: "c", Op: "f", Err: errors.New("boom")}, http.StatusUnprocessableEntity, calc.CodeOperationFailed, index(6), "c"},
+		{&calc.IncompleteError{Err: context.DeadlineExceeded, Pending: []string{"x"}}, http.StatusGatewayTimeout, calc.CodeTimeout, nil, ""},
+		{context.Canceled, 499, calc.CodeCanceled, nil, ""},
+		{calc.ErrSessionClosed, http.StatusGone, calc.CodeSessionClosed, nil, ""},
+	}
+
+	covered := make(map[calc.Code]bool)
+	for _, tt := range tests {
+		covered[tt.code] = true
+		w := httptest.NewRecorder()
+		writeError(w, httptest.NewRequest(http.MethodPost, "/calculate", nil), tt.err)
+
+		if w.Code != tt.status {
+			t.Errorf("%v: expected status %d, got %d", tt.err, tt.status, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%v: expected problem+json, got %s", tt.err, ct)
+		}
+		var p Problem
+		if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+			t.Fatalf("%v: decoding problem: %v", tt.err, err)
+		}
+		if p.Status != tt.status || p.Code != string(tt.code) || p.Type != "urn:problem-type:calculator:"+string(tt.code) ||
+			p.Title == "" || p.Detail != tt.err.Error() || p.Instance != "/calculate" || p.Var != tt.v {
+			t.Errorf("%v: unexpected problem %+v", tt.err, p)
+		}
+		if (p.Index == nil) != (tt.index == nil) || p.Index != nil && *p.Index != *tt.index {
+			t.Errorf("%v: expected index %v, got %v", tt.err, tt.index, p.Index)
+		}
+	}
+	for code := range problemStatus {
+		if !covered[code] {
+			t.Errorf("code %s is not tested", code)
+		}
+	}
+	if len(problemStatus) != len(covered) {
+		t.Errorf("expected a status for every code, got %d of %d", len(problemStatus), len(covered))
+	}
+}
@@ -30,11 +30,13 @@ type Instruction struct {
 	//
 	//	*Instruction_LeftInt
 	//	*Instruction_LeftVar
+	//	*Instruction_LeftLiteral
 	Left isInstruction_Left `protobuf_oneof:"left"`
 	// Types that are valid to be assigned to Right:
 	//
 	//	*Instruction_RightInt
 	//	*Instruction_RightVar
+	//	*Instruction_RightLiteral
 	Right         isInstruction_Right `protobuf_oneof:"right"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -116,6 +118,15 @@ func (x *Instruction) GetLeftVar() string {
 	return ""
 }
 
+func (x *Instruction) GetLeftLiteral() string {
+	if x != nil {
+		if x, ok := x.Left.(*Instruction_LeftLiteral); ok {
+			return x.LeftLiteral
+		}
+	}
+	return ""
+}
+
 func (x *Instruction) GetRight() isInstruction_Right {
 	if x != nil {
 		return x.Right
@@ -141,6 +152,15 @@ func (x *Instruction) GetRightVar() string {
 	return ""
 }
 
+func (x *Instruction) GetRightLiteral() string {
+	if x != nil {
+		if x, ok := x.Right.(*Instruction_RightLiteral); ok {
+			return x.RightLiteral
+		}
+	}
+	return ""
+}
+
 type isInstruction_Left interface {
 	isInstruction_Left()
 }
@@ -153,10 +173,16 @@ type Instruction_LeftVar struct {
 	LeftVar string `protobuf:"bytes,5,opt,name=left_var,json=leftVar,proto3,oneof"`
 }
 
+type Instruction_LeftLiteral struct {
+	LeftLiteral string `protobuf:"bytes,8,opt,name=left_literal,json=leftLiteral,proto3,oneof"`
+}
+
 func (*Instruction_LeftInt) isInstruction_Left() {}
 
 func (*Instruction_LeftVar) isInstruction_Left() {}
 
+func (*Instruction_LeftLiteral) isInstruction_Left() {}
+
 type isInstruction_Right interface {
 	isInstruction_Right()
 }
@@ -169,14 +195,26 @@ type Instruction_RightVar struct {
 	RightVar string `protobuf:"bytes,7,opt,name=right_var,json=rightVar,proto3,oneof"`
 }
 
+type Instruction_RightLiteral struct {
+	RightLiteral string `protobuf:"bytes,9,opt,name=right_literal,json=rightLiteral,proto3,oneof"`
+}
+
 func (*Instruction_RightInt) isInstruction_Right() {}
 
 func (*Instruction_RightVar) isInstruction_Right() {}
 
+func (*Instruction_RightLiteral) isInstruction_Right() {}
+
 type Result struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Var           string                 `protobuf:"bytes,1,opt,name=var,proto3" json:"var,omitempty"`
-	Value         int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Var   string                 `protobuf:"bytes,1,opt,name=var,proto3" json:"var,omitempty"`
+	Value int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	Exact string                 `protobuf:"bytes,3,opt,name=exact,proto3" json:"exact,omitempty"`
+	// version is the version of var printed in versioned calculations.
+	Version int32 `protobuf:"varint,4,opt,name=version,proto3" json:"version,omitempty"`
+	// missing is set for a print without a value, error tells why.
+	Missing       bool   `protobuf:"varint,5,opt,name=missing,proto3" json:"missing,omitempty"`
+	Error         string `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -225,9 +263,46 @@ func (x *Result) GetValue() int64 {
 	return 0
 }
 
+func (x *Result) GetExact() string {
+	if x != nil {
+		return x.Exact
+	}
+	return ""
+}
+
+func (x *Result) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Result) GetMissing() bool {
+	if x != nil {
+		return x.Missing
+	}
+	return false
+}
+
+func (x *Result) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type CalculationRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Instructions  []*Instruction         `protobuf:"bytes,1,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Instructions   []*Instruction         `protobuf:"bytes,1,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	FullEvaluation bool                   `protobuf:"varint,2,opt,name=full_evaluation,json=fullEvaluation,proto3" json:"full_evaluation,omitempty"`
+	BigInt         bool                   `protobuf:"varint,3,opt,name=big_int,json=bigInt,proto3" json:"big_int,omitempty"`
+	Trace          bool                   `protobuf:"varint,4,opt,name=trace,proto3" json:"trace,omitempty"`
+	// versioned allows assigning a variable several times, each assignment
+	// defining a new version.
+	Versioned bool `protobuf:"varint,5,opt,name=versioned,proto3" json:"versioned,omitempty"`
+	// print_mode is "final", the default, or "program" to answer a print
+	// only with a variable defined before it.
+	PrintMode     string `protobuf:"bytes,6,opt,name=print_mode,json=printMode,proto3" json:"print_mode,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -269,9 +344,46 @@ func (x *CalculationRequest) GetInstructions() []*Instruction {
 	return nil
 }
 
+func (x *CalculationRequest) GetFullEvaluation() bool {
+	if x != nil {
+		return x.FullEvaluation
+	}
+	return false
+}
+
+func (x *CalculationRequest) GetBigInt() bool {
+	if x != nil {
+		return x.BigInt
+	}
+	return false
+}
+
+func (x *CalculationRequest) GetTrace() bool {
+	if x != nil {
+		return x.Trace
+	}
+	return false
+}
+
+func (x *CalculationRequest) GetVersioned() bool {
+	if x != nil {
+		return x.Versioned
+	}
+	return false
+}
+
+func (x *CalculationRequest) GetPrintMode() string {
+	if x != nil {
+		return x.PrintMode
+	}
+	return ""
+}
+
 type CalculationResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Items         []*Result              `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Pruned        int32                  `protobuf:"varint,2,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	Trace         []*Span                `protobuf:"bytes,3,rep,name=trace,proto3" json:"trace,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -313,73 +425,1891 @@ func (x *CalculationResponse) GetItems() []*Result {
 	return nil
 }
 
-var File_grpc_calculator_proto protoreflect.FileDescriptor
+func (x *CalculationResponse) GetPruned() int32 {
+	if x != nil {
+		return x.Pruned
+	}
+	return 0
+}
 
-const file_grpc_calculator_proto_rawDesc = "" +
-	"\n" +
-	"\x15grpc/calculator.proto\x12\n" +
-	"calculator\"\xcc\x01\n" +
-	"\vInstruction\x12\x12\n" +
-	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
-	"\x02op\x18\x02 \x01(\tR\x02op\x12\x10\n" +
-	"\x03var\x18\x03 \x01(\tR\x03var\x12\x1b\n" +
-	"\bleft_int\x18\x04 \x01(\x03H\x00R\aleftInt\x12\x1b\n" +
-	"\bleft_var\x18\x05 \x01(\tH\x00R\aleftVar\x12\x1d\n" +
-	"\tright_int\x18\x06 \x01(\x03H\x01R\brightInt\x12\x1d\n" +
-	"\tright_var\x18\a \x01(\tH\x01R\brightVarB\x06\n" +
-	"\x04leftB\a\n" +
-	"\x05right\"0\n" +
-	"\x06Result\x12\x10\n" +
-	"\x03var\x18\x01 \x01(\tR\x03var\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\x03R\x05value\"Q\n" +
-	"\x12CalculationRequest\x12;\n" +
-	"\finstructions\x18\x01 \x03(\v2\x17.calculator.InstructionR\finstructions\"?\n" +
-	"\x13CalculationResponse\x12(\n" +
-	"\x05items\x18\x01 \x03(\v2\x12.calculator.ResultR\x05items2a\n" +
-	"\x11CalculatorService\x12L\n" +
-	"\tCalculate\x12\x1e.calculator.CalculationRequest\x1a\x1f.calculator.CalculationResponseB\x0eZ\f.;calculatorb\x06proto3"
+func (x *CalculationResponse) GetTrace() []*Span {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
 
-var (
-	file_grpc_calculator_proto_rawDescOnce sync.Once
-	file_grpc_calculator_proto_rawDescData []byte
-)
+// CalculationEvent is a message of CalculateStream: a print result as soon
+// as it is computed, then a summary once the calculation has finished.
+type CalculationEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*CalculationEvent_Result
+	//	*CalculationEvent_Summary
+	Event         isCalculationEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-func file_grpc_calculator_proto_rawDescGZIP() []byte {
-	file_grpc_calculator_proto_rawDescOnce.Do(func() {
-		file_grpc_calculator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grpc_calculator_proto_rawDesc), len(file_grpc_calculator_proto_rawDesc)))
-	})
-	return file_grpc_calculator_proto_rawDescData
+func (x *CalculationEvent) Reset() {
+	*x = CalculationEvent{}
+	mi := &file_grpc_calculator_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-var file_grpc_calculator_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
-var file_grpc_calculator_proto_goTypes = []any{
-	(*Instruction)(nil),         // 0: calculator.Instruction
-	(*Result)(nil),              // 1: calculator.Result
-	(*CalculationRequest)(nil),  // 2: calculator.CalculationRequest
-	(*CalculationResponse)(nil), // 3: calculator.CalculationResponse
+func (x *CalculationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
-var file_grpc_calculator_proto_depIdxs = []int32{
-	0, // 0: calculator.CalculationRequest.instructions:type_name -> calculator.Instruction
-	1, // 1: calculator.CalculationResponse.items:type_name -> calculator.Result
-	2, // 2: calculator.CalculatorService.Calculate:input_type -> calculator.CalculationRequest
-	3, // 3: calculator.CalculatorService.Calculate:output_type -> calculator.CalculationResponse
-	3, // [3:4] is the sub-list for method output_type
-	2, // [2:3] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+
+func (*CalculationEvent) ProtoMessage() {}
+
+func (x *CalculationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-func init() { file_grpc_calculator_proto_init() }
-func file_grpc_calculator_proto_init() {
-	if File_grpc_calculator_proto != nil {
-		return
+// Deprecated: Use CalculationEvent.ProtoReflect.Descriptor instead.
+func (*CalculationEvent) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CalculationEvent) GetEvent() isCalculationEvent_Event {
+	if x != nil {
+		return x.Event
 	}
-	file_grpc_calculator_proto_msgTypes[0].OneofWrappers = []any{
-		(*Instruction_LeftInt)(nil),
-		(*Instruction_LeftVar)(nil),
-		(*Instruction_RightInt)(nil),
-		(*Instruction_RightVar)(nil),
+	return nil
+}
+
+func (x *CalculationEvent) GetResult() *Result {
+	if x != nil {
+		if x, ok := x.Event.(*CalculationEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *CalculationEvent) GetSummary() *CalculationSummary {
+	if x != nil {
+		if x, ok := x.Event.(*CalculationEvent_Summary); ok {
+			return x.Summary
+		}
+	}
+	return nil
+}
+
+type isCalculationEvent_Event interface {
+	isCalculationEvent_Event()
+}
+
+type CalculationEvent_Result struct {
+	Result *Result `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type CalculationEvent_Summary struct {
+	Summary *CalculationSummary `protobuf:"bytes,2,opt,name=summary,proto3,oneof"`
+}
+
+func (*CalculationEvent_Result) isCalculationEvent_Event() {}
+
+func (*CalculationEvent_Summary) isCalculationEvent_Event() {}
+
+type CalculationSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Result              `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	Pruned        int32                  `protobuf:"varint,2,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	Trace         []*Span                `protobuf:"bytes,3,rep,name=trace,proto3" json:"trace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculationSummary) Reset() {
+	*x = CalculationSummary{}
+	mi := &file_grpc_calculator_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculationSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculationSummary) ProtoMessage() {}
+
+func (x *CalculationSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculationSummary.ProtoReflect.Descriptor instead.
+func (*CalculationSummary) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CalculationSummary) GetItems() []*Result {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *CalculationSummary) GetPruned() int32 {
+	if x != nil {
+		return x.Pruned
+	}
+	return 0
+}
+
+func (x *CalculationSummary) GetTrace() []*Span {
+	if x != nil {
+		return x.Trace
+	}
+	return nil
+}
+
+type Span struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Var           string                 `protobuf:"bytes,2,opt,name=var,proto3" json:"var,omitempty"`
+	Op            string                 `protobuf:"bytes,3,opt,name=op,proto3" json:"op,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Worker        int32                  `protobuf:"varint,5,opt,name=worker,proto3" json:"worker,omitempty"`
+	StartUnixNano int64                  `protobuf:"varint,6,opt,name=start_unix_nano,json=startUnixNano,proto3" json:"start_unix_nano,omitempty"`
+	EndUnixNano   int64                  `protobuf:"varint,7,opt,name=end_unix_nano,json=endUnixNano,proto3" json:"end_unix_nano,omitempty"`
+	QueuedUs      int64                  `protobuf:"varint,8,opt,name=queued_us,json=queuedUs,proto3" json:"queued_us,omitempty"`
+	Waits         []*DependencyWait      `protobuf:"bytes,9,rep,name=waits,proto3" json:"waits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Span) Reset() {
+	*x = Span{}
+	mi := &file_grpc_calculator_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Span) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Span) ProtoMessage() {}
+
+func (x *Span) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Span.ProtoReflect.Descriptor instead.
+func (*Span) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Span) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *Span) GetVar() string {
+	if x != nil {
+		return x.Var
+	}
+	return ""
+}
+
+func (x *Span) GetOp() string {
+	if x != nil {
+		return x.Op
+	}
+	return ""
+}
+
+func (x *Span) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Span) GetWorker() int32 {
+	if x != nil {
+		return x.Worker
+	}
+	return 0
+}
+
+func (x *Span) GetStartUnixNano() int64 {
+	if x != nil {
+		return x.StartUnixNano
+	}
+	return 0
+}
+
+func (x *Span) GetEndUnixNano() int64 {
+	if x != nil {
+		return x.EndUnixNano
+	}
+	return 0
+}
+
+func (x *Span) GetQueuedUs() int64 {
+	if x != nil {
+		return x.QueuedUs
+	}
+	return 0
+}
+
+func (x *Span) GetWaits() []*DependencyWait {
+	if x != nil {
+		return x.Waits
+	}
+	return nil
+}
+
+type DependencyWait struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Dep           string                 `protobuf:"bytes,1,opt,name=dep,proto3" json:"dep,omitempty"`
+	WaitUs        int64                  `protobuf:"varint,2,opt,name=wait_us,json=waitUs,proto3" json:"wait_us,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DependencyWait) Reset() {
+	*x = DependencyWait{}
+	mi := &file_grpc_calculator_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DependencyWait) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DependencyWait) ProtoMessage() {}
+
+func (x *DependencyWait) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DependencyWait.ProtoReflect.Descriptor instead.
+func (*DependencyWait) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DependencyWait) GetDep() string {
+	if x != nil {
+		return x.Dep
+	}
+	return ""
+}
+
+func (x *DependencyWait) GetWaitUs() int64 {
+	if x != nil {
+		return x.WaitUs
+	}
+	return 0
+}
+
+type Operation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Arity         int32                  `protobuf:"varint,2,opt,name=arity,proto3" json:"arity,omitempty"`
+	CostMs        int64                  `protobuf:"varint,3,opt,name=cost_ms,json=costMs,proto3" json:"cost_ms,omitempty"`
+	BigInt        bool                   `protobuf:"varint,4,opt,name=big_int,json=bigInt,proto3" json:"big_int,omitempty"`
+	Decimal       bool                   `protobuf:"varint,5,opt,name=decimal,proto3" json:"decimal,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Operation) Reset() {
+	*x = Operation{}
+	mi := &file_grpc_calculator_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Operation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Operation) ProtoMessage() {}
+
+func (x *Operation) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Operation.ProtoReflect.Descriptor instead.
+func (*Operation) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Operation) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Operation) GetArity() int32 {
+	if x != nil {
+		return x.Arity
+	}
+	return 0
+}
+
+func (x *Operation) GetCostMs() int64 {
+	if x != nil {
+		return x.CostMs
+	}
+	return 0
+}
+
+func (x *Operation) GetBigInt() bool {
+	if x != nil {
+		return x.BigInt
+	}
+	return false
+}
+
+func (x *Operation) GetDecimal() bool {
+	if x != nil {
+		return x.Decimal
+	}
+	return false
+}
+
+type ListOperationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsRequest) Reset() {
+	*x = ListOperationsRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsRequest) ProtoMessage() {}
+
+func (x *ListOperationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsRequest.ProtoReflect.Descriptor instead.
+func (*ListOperationsRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{9}
+}
+
+type ListOperationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Operation           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOperationsResponse) Reset() {
+	*x = ListOperationsResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOperationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOperationsResponse) ProtoMessage() {}
+
+func (x *ListOperationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOperationsResponse.ProtoReflect.Descriptor instead.
+func (*ListOperationsResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListOperationsResponse) GetItems() []*Operation {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type PrepareProgramRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Instructions   []*Instruction         `protobuf:"bytes,1,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	FullEvaluation bool                   `protobuf:"varint,2,opt,name=full_evaluation,json=fullEvaluation,proto3" json:"full_evaluation,omitempty"`
+	BigInt         bool                   `protobuf:"varint,3,opt,name=big_int,json=bigInt,proto3" json:"big_int,omitempty"`
+	Versioned      bool                   `protobuf:"varint,4,opt,name=versioned,proto3" json:"versioned,omitempty"`
+	PrintMode      string                 `protobuf:"bytes,5,opt,name=print_mode,json=printMode,proto3" json:"print_mode,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PrepareProgramRequest) Reset() {
+	*x = PrepareProgramRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareProgramRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareProgramRequest) ProtoMessage() {}
+
+func (x *PrepareProgramRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareProgramRequest.ProtoReflect.Descriptor instead.
+func (*PrepareProgramRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PrepareProgramRequest) GetInstructions() []*Instruction {
+	if x != nil {
+		return x.Instructions
+	}
+	return nil
+}
+
+func (x *PrepareProgramRequest) GetFullEvaluation() bool {
+	if x != nil {
+		return x.FullEvaluation
+	}
+	return false
+}
+
+func (x *PrepareProgramRequest) GetBigInt() bool {
+	if x != nil {
+		return x.BigInt
+	}
+	return false
+}
+
+func (x *PrepareProgramRequest) GetVersioned() bool {
+	if x != nil {
+		return x.Versioned
+	}
+	return false
+}
+
+func (x *PrepareProgramRequest) GetPrintMode() string {
+	if x != nil {
+		return x.PrintMode
+	}
+	return ""
+}
+
+type PrepareProgramResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Params        []string               `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty"`
+	EstimateMs    int64                  `protobuf:"varint,3,opt,name=estimate_ms,json=estimateMs,proto3" json:"estimate_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PrepareProgramResponse) Reset() {
+	*x = PrepareProgramResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PrepareProgramResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PrepareProgramResponse) ProtoMessage() {}
+
+func (x *PrepareProgramResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PrepareProgramResponse.ProtoReflect.Descriptor instead.
+func (*PrepareProgramResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *PrepareProgramResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PrepareProgramResponse) GetParams() []string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+func (x *PrepareProgramResponse) GetEstimateMs() int64 {
+	if x != nil {
+		return x.EstimateMs
+	}
+	return 0
+}
+
+type RunProgramRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Params        map[string]string      `protobuf:"bytes,2,rep,name=params,proto3" json:"params,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunProgramRequest) Reset() {
+	*x = RunProgramRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunProgramRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunProgramRequest) ProtoMessage() {}
+
+func (x *RunProgramRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunProgramRequest.ProtoReflect.Descriptor instead.
+func (*RunProgramRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RunProgramRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RunProgramRequest) GetParams() map[string]string {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type DeleteProgramRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProgramRequest) Reset() {
+	*x = DeleteProgramRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProgramRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProgramRequest) ProtoMessage() {}
+
+func (x *DeleteProgramRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProgramRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProgramRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *DeleteProgramRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteProgramResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProgramResponse) Reset() {
+	*x = DeleteProgramResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProgramResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProgramResponse) ProtoMessage() {}
+
+func (x *DeleteProgramResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProgramResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProgramResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{15}
+}
+
+type CreateSessionRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	BigInt bool                   `protobuf:"varint,1,opt,name=big_int,json=bigInt,proto3" json:"big_int,omitempty"`
+	// reactive sessions allow redefining variables, recomputing the
+	// variables that depend on them.
+	Reactive      bool `protobuf:"varint,2,opt,name=reactive,proto3" json:"reactive,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionRequest) Reset() {
+	*x = CreateSessionRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionRequest) ProtoMessage() {}
+
+func (x *CreateSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionRequest.ProtoReflect.Descriptor instead.
+func (*CreateSessionRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *CreateSessionRequest) GetBigInt() bool {
+	if x != nil {
+		return x.BigInt
+	}
+	return false
+}
+
+func (x *CreateSessionRequest) GetReactive() bool {
+	if x != nil {
+		return x.Reactive
+	}
+	return false
+}
+
+type CreateSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSessionResponse) Reset() {
+	*x = CreateSessionResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSessionResponse) ProtoMessage() {}
+
+func (x *CreateSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSessionResponse.ProtoReflect.Descriptor instead.
+func (*CreateSessionResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CreateSessionResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateSessionResponse) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+type CalculateInSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Instructions  []*Instruction         `protobuf:"bytes,2,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CalculateInSessionRequest) Reset() {
+	*x = CalculateInSessionRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CalculateInSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CalculateInSessionRequest) ProtoMessage() {}
+
+func (x *CalculateInSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CalculateInSessionRequest.ProtoReflect.Descriptor instead.
+func (*CalculateInSessionRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *CalculateInSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CalculateInSessionRequest) GetInstructions() []*Instruction {
+	if x != nil {
+		return x.Instructions
+	}
+	return nil
+}
+
+type ListSessionVarsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionVarsRequest) Reset() {
+	*x = ListSessionVarsRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionVarsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionVarsRequest) ProtoMessage() {}
+
+func (x *ListSessionVarsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionVarsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionVarsRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListSessionVarsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Variable is a variable of a session; value and exact are only set once
+// its status is "ok".
+type Variable struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Var           string                 `protobuf:"bytes,1,opt,name=var,proto3" json:"var,omitempty"`
+	Value         int64                  `protobuf:"varint,2,opt,name=value,proto3" json:"value,omitempty"`
+	Exact         string                 `protobuf:"bytes,3,opt,name=exact,proto3" json:"exact,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Variable) Reset() {
+	*x = Variable{}
+	mi := &file_grpc_calculator_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Variable) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Variable) ProtoMessage() {}
+
+func (x *Variable) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Variable.ProtoReflect.Descriptor instead.
+func (*Variable) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *Variable) GetVar() string {
+	if x != nil {
+		return x.Var
+	}
+	return ""
+}
+
+func (x *Variable) GetValue() int64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Variable) GetExact() string {
+	if x != nil {
+		return x.Exact
+	}
+	return ""
+}
+
+func (x *Variable) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Variable) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type ListSessionVarsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*Variable            `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionVarsResponse) Reset() {
+	*x = ListSessionVarsResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionVarsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionVarsResponse) ProtoMessage() {}
+
+func (x *ListSessionVarsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionVarsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionVarsResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListSessionVarsResponse) GetItems() []*Variable {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type DeleteSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSessionRequest) Reset() {
+	*x = DeleteSessionRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSessionRequest) ProtoMessage() {}
+
+func (x *DeleteSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSessionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSessionRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeleteSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteSessionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSessionResponse) Reset() {
+	*x = DeleteSessionResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSessionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSessionResponse) ProtoMessage() {}
+
+func (x *DeleteSessionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSessionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSessionResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{23}
+}
+
+// WatchSessionRequest selects the variables to watch, all if vars is empty.
+type WatchSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Vars          []string               `protobuf:"bytes,2,rep,name=vars,proto3" json:"vars,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchSessionRequest) Reset() {
+	*x = WatchSessionRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchSessionRequest) ProtoMessage() {}
+
+func (x *WatchSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchSessionRequest.ProtoReflect.Descriptor instead.
+func (*WatchSessionRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WatchSessionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *WatchSessionRequest) GetVars() []string {
+	if x != nil {
+		return x.Vars
+	}
+	return nil
+}
+
+type ExplainRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Instructions   []*Instruction         `protobuf:"bytes,1,rep,name=instructions,proto3" json:"instructions,omitempty"`
+	FullEvaluation bool                   `protobuf:"varint,2,opt,name=full_evaluation,json=fullEvaluation,proto3" json:"full_evaluation,omitempty"`
+	BigInt         bool                   `protobuf:"varint,3,opt,name=big_int,json=bigInt,proto3" json:"big_int,omitempty"`
+	Format         string                 `protobuf:"bytes,4,opt,name=format,proto3" json:"format,omitempty"`
+	Versioned      bool                   `protobuf:"varint,5,opt,name=versioned,proto3" json:"versioned,omitempty"`
+	PrintMode      string                 `protobuf:"bytes,6,opt,name=print_mode,json=printMode,proto3" json:"print_mode,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExplainRequest) Reset() {
+	*x = ExplainRequest{}
+	mi := &file_grpc_calculator_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainRequest) ProtoMessage() {}
+
+func (x *ExplainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainRequest.ProtoReflect.Descriptor instead.
+func (*ExplainRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ExplainRequest) GetInstructions() []*Instruction {
+	if x != nil {
+		return x.Instructions
+	}
+	return nil
+}
+
+func (x *ExplainRequest) GetFullEvaluation() bool {
+	if x != nil {
+		return x.FullEvaluation
+	}
+	return false
+}
+
+func (x *ExplainRequest) GetBigInt() bool {
+	if x != nil {
+		return x.BigInt
+	}
+	return false
+}
+
+func (x *ExplainRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ExplainRequest) GetVersioned() bool {
+	if x != nil {
+		return x.Versioned
+	}
+	return false
+}
+
+func (x *ExplainRequest) GetPrintMode() string {
+	if x != nil {
+		return x.PrintMode
+	}
+	return ""
+}
+
+type PlanNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Var           string                 `protobuf:"bytes,2,opt,name=var,proto3" json:"var,omitempty"`
+	Expr          string                 `protobuf:"bytes,3,opt,name=expr,proto3" json:"expr,omitempty"`
+	Deps          []string               `protobuf:"bytes,4,rep,name=deps,proto3" json:"deps,omitempty"`
+	Level         int32                  `protobuf:"varint,5,opt,name=level,proto3" json:"level,omitempty"`
+	EstimateMs    int64                  `protobuf:"varint,6,opt,name=estimate_ms,json=estimateMs,proto3" json:"estimate_ms,omitempty"`
+	Pruned        bool                   `protobuf:"varint,7,opt,name=pruned,proto3" json:"pruned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanNode) Reset() {
+	*x = PlanNode{}
+	mi := &file_grpc_calculator_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanNode) ProtoMessage() {}
+
+func (x *PlanNode) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanNode.ProtoReflect.Descriptor instead.
+func (*PlanNode) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *PlanNode) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *PlanNode) GetVar() string {
+	if x != nil {
+		return x.Var
+	}
+	return ""
+}
+
+func (x *PlanNode) GetExpr() string {
+	if x != nil {
+		return x.Expr
+	}
+	return ""
+}
+
+func (x *PlanNode) GetDeps() []string {
+	if x != nil {
+		return x.Deps
+	}
+	return nil
+}
+
+func (x *PlanNode) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *PlanNode) GetEstimateMs() int64 {
+	if x != nil {
+		return x.EstimateMs
+	}
+	return 0
+}
+
+func (x *PlanNode) GetPruned() bool {
+	if x != nil {
+		return x.Pruned
+	}
+	return false
+}
+
+type PlanLevel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vars          []string               `protobuf:"bytes,1,rep,name=vars,proto3" json:"vars,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlanLevel) Reset() {
+	*x = PlanLevel{}
+	mi := &file_grpc_calculator_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlanLevel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlanLevel) ProtoMessage() {}
+
+func (x *PlanLevel) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlanLevel.ProtoReflect.Descriptor instead.
+func (*PlanLevel) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *PlanLevel) GetVars() []string {
+	if x != nil {
+		return x.Vars
+	}
+	return nil
+}
+
+type ExplainResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Nodes          []*PlanNode            `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Levels         []*PlanLevel           `protobuf:"bytes,2,rep,name=levels,proto3" json:"levels,omitempty"`
+	CriticalPath   []string               `protobuf:"bytes,3,rep,name=critical_path,json=criticalPath,proto3" json:"critical_path,omitempty"`
+	CriticalPathMs int64                  `protobuf:"varint,4,opt,name=critical_path_ms,json=criticalPathMs,proto3" json:"critical_path_ms,omitempty"`
+	EstimateMs     int64                  `protobuf:"varint,5,opt,name=estimate_ms,json=estimateMs,proto3" json:"estimate_ms,omitempty"`
+	Pruned         []string               `protobuf:"bytes,6,rep,name=pruned,proto3" json:"pruned,omitempty"`
+	Rendering      string                 `protobuf:"bytes,7,opt,name=rendering,proto3" json:"rendering,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExplainResponse) Reset() {
+	*x = ExplainResponse{}
+	mi := &file_grpc_calculator_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExplainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExplainResponse) ProtoMessage() {}
+
+func (x *ExplainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExplainResponse.ProtoReflect.Descriptor instead.
+func (*ExplainResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ExplainResponse) GetNodes() []*PlanNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetLevels() []*PlanLevel {
+	if x != nil {
+		return x.Levels
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetCriticalPath() []string {
+	if x != nil {
+		return x.CriticalPath
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetCriticalPathMs() int64 {
+	if x != nil {
+		return x.CriticalPathMs
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetEstimateMs() int64 {
+	if x != nil {
+		return x.EstimateMs
+	}
+	return 0
+}
+
+func (x *ExplainResponse) GetPruned() []string {
+	if x != nil {
+		return x.Pruned
+	}
+	return nil
+}
+
+func (x *ExplainResponse) GetRendering() string {
+	if x != nil {
+		return x.Rendering
+	}
+	return ""
+}
+
+// SessionEvent answers the instruction with the given index, numbered from 0
+// in the order sent on the Session stream: a print with its result, or any
+// instruction with the error that rejected it or kept its value from being
+// computed.
+type SessionEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Index int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SessionEvent_Result
+	//	*SessionEvent_Error
+	Event         isSessionEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionEvent) Reset() {
+	*x = SessionEvent{}
+	mi := &file_grpc_calculator_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionEvent) ProtoMessage() {}
+
+func (x *SessionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_calculator_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionEvent.ProtoReflect.Descriptor instead.
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return file_grpc_calculator_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SessionEvent) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *SessionEvent) GetEvent() isSessionEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetResult() *Result {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetError() string {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_Error); ok {
+			return x.Error
+		}
+	}
+	return ""
+}
+
+type isSessionEvent_Event interface {
+	isSessionEvent_Event()
+}
+
+type SessionEvent_Result struct {
+	Result *Result `protobuf:"bytes,2,opt,name=result,proto3,oneof"`
+}
+
+type SessionEvent_Error struct {
+	Error string `protobuf:"bytes,3,opt,name=error,proto3,oneof"`
+}
+
+func (*SessionEvent_Result) isSessionEvent_Event() {}
+
+func (*SessionEvent_Error) isSessionEvent_Event() {}
+
+var File_grpc_calculator_proto protoreflect.FileDescriptor
+
+const file_grpc_calculator_proto_rawDesc = "" +
+	"\n" +
+	"\x15grpc/calculator.proto\x12\n" +
+	"calculator\"\x98\x02\n" +
+	"\vInstruction\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x0e\n" +
+	"\x02op\x18\x02 \x01(\tR\x02op\x12\x10\n" +
+	"\x03var\x18\x03 \x01(\tR\x03var\x12\x1b\n" +
+	"\bleft_int\x18\x04 \x01(\x03H\x00R\aleftInt\x12\x1b\n" +
+	"\bleft_var\x18\x05 \x01(\tH\x00R\aleftVar\x12#\n" +
+	"\fleft_literal\x18\b \x01(\tH\x00R\vleftLiteral\x12\x1d\n" +
+	"\tright_int\x18\x06 \x01(\x03H\x01R\brightInt\x12\x1d\n" +
+	"\tright_var\x18\a \x01(\tH\x01R\brightVar\x12%\n" +
+	"\rright_literal\x18\t \x01(\tH\x01R\frightLiteralB\x06\n" +
+	"\x04leftB\a\n" +
+	"\x05right\"\x90\x01\n" +
+	"\x06Result\x12\x10\n" +
+	"\x03var\x18\x01 \x01(\tR\x03var\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\x12\x14\n" +
+	"\x05exact\x18\x03 \x01(\tR\x05exact\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\x05R\aversion\x12\x18\n" +
+	"\amissing\x18\x05 \x01(\bR\amissing\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\xe6\x01\n" +
+	"\x12CalculationRequest\x12;\n" +
+	"\finstructions\x18\x01 \x03(\v2\x17.calculator.InstructionR\finstructions\x12'\n" +
+	"\x0ffull_evaluation\x18\x02 \x01(\bR\x0efullEvaluation\x12\x17\n" +
+	"\abig_int\x18\x03 \x01(\bR\x06bigInt\x12\x14\n" +
+	"\x05trace\x18\x04 \x01(\bR\x05trace\x12\x1c\n" +
+	"\tversioned\x18\x05 \x01(\bR\tversioned\x12\x1d\n" +
+	"\n" +
+	"print_mode\x18\x06 \x01(\tR\tprintMode\"\x7f\n" +
+	"\x13CalculationResponse\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.calculator.ResultR\x05items\x12\x16\n" +
+	"\x06pruned\x18\x02 \x01(\x05R\x06pruned\x12&\n" +
+	"\x05trace\x18\x03 \x03(\v2\x10.calculator.SpanR\x05trace\"\x85\x01\n" +
+	"\x10CalculationEvent\x12,\n" +
+	"\x06result\x18\x01 \x01(\v2\x12.calculator.ResultH\x00R\x06result\x12:\n" +
+	"\asummary\x18\x02 \x01(\v2\x1e.calculator.CalculationSummaryH\x00R\asummaryB\a\n" +
+	"\x05event\"~\n" +
+	"\x12CalculationSummary\x12(\n" +
+	"\x05items\x18\x01 \x03(\v2\x12.calculator.ResultR\x05items\x12\x16\n" +
+	"\x06pruned\x18\x02 \x01(\x05R\x06pruned\x12&\n" +
+	"\x05trace\x18\x03 \x03(\v2\x10.calculator.SpanR\x05trace\"\x89\x02\n" +
+	"\x04Span\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x10\n" +
+	"\x03var\x18\x02 \x01(\tR\x03var\x12\x0e\n" +
+	"\x02op\x18\x03 \x01(\tR\x02op\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x16\n" +
+	"\x06worker\x18\x05 \x01(\x05R\x06worker\x12&\n" +
+	"\x0fstart_unix_nano\x18\x06 \x01(\x03R\rstartUnixNano\x12\"\n" +
+	"\rend_unix_nano\x18\a \x01(\x03R\vendUnixNano\x12\x1b\n" +
+	"\tqueued_us\x18\b \x01(\x03R\bqueuedUs\x120\n" +
+	"\x05waits\x18\t \x03(\v2\x1a.calculator.DependencyWaitR\x05waits\";\n" +
+	"\x0eDependencyWait\x12\x10\n" +
+	"\x03dep\x18\x01 \x01(\tR\x03dep\x12\x17\n" +
+	"\await_us\x18\x02 \x01(\x03R\x06waitUs\"\x81\x01\n" +
+	"\tOperation\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05arity\x18\x02 \x01(\x05R\x05arity\x12\x17\n" +
+	"\acost_ms\x18\x03 \x01(\x03R\x06costMs\x12\x17\n" +
+	"\abig_int\x18\x04 \x01(\bR\x06bigInt\x12\x18\n" +
+	"\adecimal\x18\x05 \x01(\bR\adecimal\"\x17\n" +
+	"\x15ListOperationsRequest\"E\n" +
+	"\x16ListOperationsResponse\x12+\n" +
+	"\x05items\x18\x01 \x03(\v2\x15.calculator.OperationR\x05items\"\xd3\x01\n" +
+	"\x15PrepareProgramRequest\x12;\n" +
+	"\finstructions\x18\x01 \x03(\v2\x17.calculator.InstructionR\finstructions\x12'\n" +
+	"\x0ffull_evaluation\x18\x02 \x01(\bR\x0efullEvaluation\x12\x17\n" +
+	"\abig_int\x18\x03 \x01(\bR\x06bigInt\x12\x1c\n" +
+	"\tversioned\x18\x04 \x01(\bR\tversioned\x12\x1d\n" +
+	"\n" +
+	"print_mode\x18\x05 \x01(\tR\tprintMode\"a\n" +
+	"\x16PrepareProgramResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
+	"\x06params\x18\x02 \x03(\tR\x06params\x12\x1f\n" +
+	"\vestimate_ms\x18\x03 \x01(\x03R\n" +
+	"estimateMs\"\xa1\x01\n" +
+	"\x11RunProgramRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12A\n" +
+	"\x06params\x18\x02 \x03(\v2).calculator.RunProgramRequest.ParamsEntryR\x06params\x1a9\n" +
+	"\vParamsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"&\n" +
+	"\x14DeleteProgramRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteProgramResponse\"K\n" +
+	"\x14CreateSessionRequest\x12\x17\n" +
+	"\abig_int\x18\x01 \x01(\bR\x06bigInt\x12\x1a\n" +
+	"\breactive\x18\x02 \x01(\bR\breactive\"H\n" +
+	"\x15CreateSessionResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"h\n" +
+	"\x19CalculateInSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\finstructions\x18\x02 \x03(\v2\x17.calculator.InstructionR\finstructions\"(\n" +
+	"\x16ListSessionVarsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"v\n" +
+	"\bVariable\x12\x10\n" +
+	"\x03var\x18\x01 \x01(\tR\x03var\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value\x12\x14\n" +
+	"\x05exact\x18\x03 \x01(\tR\x05exact\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"E\n" +
+	"\x17ListSessionVarsResponse\x12*\n" +
+	"\x05items\x18\x01 \x03(\v2\x14.calculator.VariableR\x05items\"&\n" +
+	"\x14DeleteSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x17\n" +
+	"\x15DeleteSessionResponse\"9\n" +
+	"\x13WatchSessionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04vars\x18\x02 \x03(\tR\x04vars\"\xe4\x01\n" +
+	"\x0eExplainRequest\x12;\n" +
+	"\finstructions\x18\x01 \x03(\v2\x17.calculator.InstructionR\finstructions\x12'\n" +
+	"\x0ffull_evaluation\x18\x02 \x01(\bR\x0efullEvaluation\x12\x17\n" +
+	"\abig_int\x18\x03 \x01(\bR\x06bigInt\x12\x16\n" +
+	"\x06format\x18\x04 \x01(\tR\x06format\x12\x1c\n" +
+	"\tversioned\x18\x05 \x01(\bR\tversioned\x12\x1d\n" +
+	"\n" +
+	"print_mode\x18\x06 \x01(\tR\tprintMode\"\xa9\x01\n" +
+	"\bPlanNode\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x10\n" +
+	"\x03var\x18\x02 \x01(\tR\x03var\x12\x12\n" +
+	"\x04expr\x18\x03 \x01(\tR\x04expr\x12\x12\n" +
+	"\x04deps\x18\x04 \x03(\tR\x04deps\x12\x14\n" +
+	"\x05level\x18\x05 \x01(\x05R\x05level\x12\x1f\n" +
+	"\vestimate_ms\x18\x06 \x01(\x03R\n" +
+	"estimateMs\x12\x16\n" +
+	"\x06pruned\x18\a \x01(\bR\x06pruned\"\x1f\n" +
+	"\tPlanLevel\x12\x12\n" +
+	"\x04vars\x18\x01 \x03(\tR\x04vars\"\x92\x02\n" +
+	"\x0fExplainResponse\x12*\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x14.calculator.PlanNodeR\x05nodes\x12-\n" +
+	"\x06levels\x18\x02 \x03(\v2\x15.calculator.PlanLevelR\x06levels\x12#\n" +
+	"\rcritical_path\x18\x03 \x03(\tR\fcriticalPath\x12(\n" +
+	"\x10critical_path_ms\x18\x04 \x01(\x03R\x0ecriticalPathMs\x12\x1f\n" +
+	"\vestimate_ms\x18\x05 \x01(\x03R\n" +
+	"estimateMs\x12\x16\n" +
+	"\x06pruned\x18\x06 \x03(\tR\x06pruned\x12\x1c\n" +
+	"\trendering\x18\a \x01(\tR\trendering\"s\n" +
+	"\fSessionEvent\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12,\n" +
+	"\x06result\x18\x02 \x01(\v2\x12.calculator.ResultH\x00R\x06result\x12\x16\n" +
+	"\x05error\x18\x03 \x01(\tH\x00R\x05errorB\a\n" +
+	"\x05event2\xbf\b\n" +
+	"\x11CalculatorService\x12L\n" +
+	"\tCalculate\x12\x1e.calculator.CalculationRequest\x1a\x1f.calculator.CalculationResponse\x12Q\n" +
+	"\x0fCalculateStream\x12\x1e.calculator.CalculationRequest\x1a\x1c.calculator.CalculationEvent0\x01\x12W\n" +
+	"\x0eListOperations\x12!.calculator.ListOperationsRequest\x1a\".calculator.ListOperationsResponse\x12W\n" +
+	"\x0ePrepareProgram\x12!.calculator.PrepareProgramRequest\x1a\".calculator.PrepareProgramResponse\x12L\n" +
+	"\n" +
+	"RunProgram\x12\x1d.calculator.RunProgramRequest\x1a\x1f.calculator.CalculationResponse\x12T\n" +
+	"\rDeleteProgram\x12 .calculator.DeleteProgramRequest\x1a!.calculator.DeleteProgramResponse\x12B\n" +
+	"\aExplain\x12\x1a.calculator.ExplainRequest\x1a\x1b.calculator.ExplainResponse\x12@\n" +
+	"\aSession\x12\x17.calculator.Instruction\x1a\x18.calculator.SessionEvent(\x010\x01\x12T\n" +
+	"\rCreateSession\x12 .calculator.CreateSessionRequest\x1a!.calculator.CreateSessionResponse\x12\\\n" +
+	"\x12CalculateInSession\x12%.calculator.CalculateInSessionRequest\x1a\x1f.calculator.CalculationResponse\x12Z\n" +
+	"\x0fListSessionVars\x12\".calculator.ListSessionVarsRequest\x1a#.calculator.ListSessionVarsResponse\x12T\n" +
+	"\rDeleteSession\x12 .calculator.DeleteSessionRequest\x1a!.calculator.DeleteSessionResponse\x12G\n" +
+	"\fWatchSession\x12\x1f.calculator.WatchSessionRequest\x1a\x14.calculator.Variable0\x01B\x0eZ\f.;calculatorb\x06proto3"
+
+var (
+	file_grpc_calculator_proto_rawDescOnce sync.Once
+	file_grpc_calculator_proto_rawDescData []byte
+)
+
+func file_grpc_calculator_proto_rawDescGZIP() []byte {
+	file_grpc_calculator_proto_rawDescOnce.Do(func() {
+		file_grpc_calculator_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grpc_calculator_proto_rawDesc), len(file_grpc_calculator_proto_rawDesc)))
+	})
+	return file_grpc_calculator_proto_rawDescData
+}
+
+var file_grpc_calculator_proto_msgTypes = make([]protoimpl.MessageInfo, 31)
+var file_grpc_calculator_proto_goTypes = []any{
+	(*Instruction)(nil),               // 0: calculator.Instruction
+	(*Result)(nil),                    // 1: calculator.Result
+	(*CalculationRequest)(nil),        // 2: calculator.CalculationRequest
+	(*CalculationResponse)(nil),       // 3: calculator.CalculationResponse
+	(*CalculationEvent)(nil),          // 4: calculator.CalculationEvent
+	(*CalculationSummary)(nil),        // 5: calculator.CalculationSummary
+	(*Span)(nil),                      // 6: calculator.Span
+	(*DependencyWait)(nil),            // 7: calculator.DependencyWait
+	(*Operation)(nil),                 // 8: calculator.Operation
+	(*ListOperationsRequest)(nil),     // 9: calculator.ListOperationsRequest
+	(*ListOperationsResponse)(nil),    // 10: calculator.ListOperationsResponse
+	(*PrepareProgramRequest)(nil),     // 11: calculator.PrepareProgramRequest
+	(*PrepareProgramResponse)(nil),    // 12: calculator.PrepareProgramResponse
+	(*RunProgramRequest)(nil),         // 13: calculator.RunProgramRequest
+	(*DeleteProgramRequest)(nil),      // 14: calculator.DeleteProgramRequest
+	(*DeleteProgramResponse)(nil),     // 15: calculator.DeleteProgramResponse
+	(*CreateSessionRequest)(nil),      // 16: calculator.CreateSessionRequest
+	(*CreateSessionResponse)(nil),     // 17: calculator.CreateSessionResponse
+	(*CalculateInSessionRequest)(nil), // 18: calculator.CalculateInSessionRequest
+	(*ListSessionVarsRequest)(nil),    // 19: calculator.ListSessionVarsRequest
+	(*Variable)(nil),                  // 20: calculator.Variable
+	(*ListSessionVarsResponse)(nil),   // 21: calculator.ListSessionVarsResponse
+	(*DeleteSessionRequest)(nil),      // 22: calculator.DeleteSessionRequest
+	(*DeleteSessionResponse)(nil),     // 23: calculator.DeleteSessionResponse
+	(*WatchSessionRequest)(nil),       // 24: calculator.WatchSessionRequest
+	(*ExplainRequest)(nil),            // 25: calculator.ExplainRequest
+	(*PlanNode)(nil),                  // 26: calculator.PlanNode
+	(*PlanLevel)(nil),                 // 27: calculator.PlanLevel
+	(*ExplainResponse)(nil),           // 28: calculator.ExplainResponse
+	(*SessionEvent)(nil),              // 29: calculator.SessionEvent
+	nil,                               // 30: calculator.RunProgramRequest.ParamsEntry
+}
+var file_grpc_calculator_proto_depIdxs = []int32{
+	0,  // 0: calculator.CalculationRequest.instructions:type_name -> calculator.Instruction
+	1,  // 1: calculator.CalculationResponse.items:type_name -> calculator.Result
+	6,  // 2: calculator.CalculationResponse.trace:type_name -> calculator.Span
+	1,  // 3: calculator.CalculationEvent.result:type_name -> calculator.Result
+	5,  // 4: calculator.CalculationEvent.summary:type_name -> calculator.CalculationSummary
+	1,  // 5: calculator.CalculationSummary.items:type_name -> calculator.Result
+	6,  // 6: calculator.CalculationSummary.trace:type_name -> calculator.Span
+	7,  // 7: calculator.Span.waits:type_name -> calculator.DependencyWait
+	8,  // 8: calculator.ListOperationsResponse.items:type_name -> calculator.Operation
+	0,  // 9: calculator.PrepareProgramRequest.instructions:type_name -> calculator.Instruction
+	30, // 10: calculator.RunProgramRequest.params:type_name -> calculator.RunProgramRequest.ParamsEntry
+	0,  // 11: calculator.CalculateInSessionRequest.instructions:type_name -> calculator.Instruction
+	20, // 12: calculator.ListSessionVarsResponse.items:type_name -> calculator.Variable
+	0,  // 13: calculator.ExplainRequest.instructions:type_name -> calculator.Instruction
+	26, // 14: calculator.ExplainResponse.nodes:type_name -> calculator.PlanNode
+	27, // 15: calculator.ExplainResponse.levels:type_name -> calculator.PlanLevel
+	1,  // 16: calculator.SessionEvent.result:type_name -> calculator.Result
+	2,  // 17: calculator.CalculatorService.Calculate:input_type -> calculator.CalculationRequest
+	2,  // 18: calculator.CalculatorService.CalculateStream:input_type -> calculator.CalculationRequest
+	9,  // 19: calculator.CalculatorService.ListOperations:input_type -> calculator.ListOperationsRequest
+	11, // 20: calculator.CalculatorService.PrepareProgram:input_type -> calculator.PrepareProgramRequest
+	13, // 21: calculator.CalculatorService.RunProgram:input_type -> calculator.RunProgramRequest
+	14, // 22: calculator.CalculatorService.DeleteProgram:input_type -> calculator.DeleteProgramRequest
+	25, // 23: calculator.CalculatorService.Explain:input_type -> calculator.ExplainRequest
+	0,  // 24: calculator.CalculatorService.Session:input_type -> calculator.Instruction
+	16, // 25: calculator.CalculatorService.CreateSession:input_type -> calculator.CreateSessionRequest
+	18, // 26: calculator.CalculatorService.CalculateInSession:input_type -> calculator.CalculateInSessionRequest
+	19, // 27: calculator.CalculatorService.ListSessionVars:input_type -> calculator.ListSessionVarsRequest
+	22, // 28: calculator.CalculatorService.DeleteSession:input_type -> calculator.DeleteSessionRequest
+	24, // 29: calculator.CalculatorService.WatchSession:input_type -> calculator.WatchSessionRequest
+	3,  // 30: calculator.CalculatorService.Calculate:output_type -> calculator.CalculationResponse
+	4,  // 31: calculator.CalculatorService.CalculateStream:output_type -> calculator.CalculationEvent
+	10, // 32: calculator.CalculatorService.ListOperations:output_type -> calculator.ListOperationsResponse
+	12, // 33: calculator.CalculatorService.PrepareProgram:output_type -> calculator.PrepareProgramResponse
+	3,  // 34: calculator.CalculatorService.RunProgram:output_type -> calculator.CalculationResponse
+	15, // 35: calculator.CalculatorService.DeleteProgram:output_type -> calculator.DeleteProgramResponse
+	28, // 36: calculator.CalculatorService.Explain:output_type -> calculator.ExplainResponse
+	29, // 37: calculator.CalculatorService.Session:output_type -> calculator.SessionEvent
+	17, // 38: calculator.CalculatorService.CreateSession:output_type -> calculator.CreateSessionResponse
+	3,  // 39: calculator.CalculatorService.CalculateInSession:output_type -> calculator.CalculationResponse
+	21, // 40: calculator.CalculatorService.ListSessionVars:output_type -> calculator.ListSessionVarsResponse
+	23, // 41: calculator.CalculatorService.DeleteSession:output_type -> calculator.DeleteSessionResponse
+	20, // 42: calculator.CalculatorService.WatchSession:output_type -> calculator.Variable
+	30, // [30:43] is the sub-list for method output_type
+	17, // [17:30] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_grpc_calculator_proto_init() }
+func file_grpc_calculator_proto_init() {
+	if File_grpc_calculator_proto != nil {
+		return
+	}
+	file_grpc_calculator_proto_msgTypes[0].OneofWrappers = []any{
+		(*Instruction_LeftInt)(nil),
+		(*Instruction_LeftVar)(nil),
+		(*Instruction_LeftLiteral)(nil),
+		(*Instruction_RightInt)(nil),
+		(*Instruction_RightVar)(nil),
+		(*Instruction_RightLiteral)(nil),
+	}
+	file_grpc_calculator_proto_msgTypes[4].OneofWrappers = []any{
+		(*CalculationEvent_Result)(nil),
+		(*CalculationEvent_Summary)(nil),
+	}
+	file_grpc_calculator_proto_msgTypes[29].OneofWrappers = []any{
+		(*SessionEvent_Result)(nil),
+		(*SessionEvent_Error)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -387,7 +2317,7 @@ func file_grpc_calculator_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grpc_calculator_proto_rawDesc), len(file_grpc_calculator_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   4,
+			NumMessages:   31,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
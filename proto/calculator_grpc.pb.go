@@ -19,7 +19,19 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CalculatorService_Calculate_FullMethodName = "/calculator.CalculatorService/Calculate"
+	CalculatorService_Calculate_FullMethodName          = "/calculator.CalculatorService/Calculate"
+	CalculatorService_CalculateStream_FullMethodName    = "/calculator.CalculatorService/CalculateStream"
+	CalculatorService_ListOperations_FullMethodName     = "/calculator.CalculatorService/ListOperations"
+	CalculatorService_PrepareProgram_FullMethodName     = "/calculator.CalculatorService/PrepareProgram"
+	CalculatorService_RunProgram_FullMethodName         = "/calculator.CalculatorService/RunProgram"
+	CalculatorService_DeleteProgram_FullMethodName      = "/calculator.CalculatorService/DeleteProgram"
+	CalculatorService_Explain_FullMethodName            = "/calculator.CalculatorService/Explain"
+	CalculatorService_Session_FullMethodName            = "/calculator.CalculatorService/Session"
+	CalculatorService_CreateSession_FullMethodName      = "/calculator.CalculatorService/CreateSession"
+	CalculatorService_CalculateInSession_FullMethodName = "/calculator.CalculatorService/CalculateInSession"
+	CalculatorService_ListSessionVars_FullMethodName    = "/calculator.CalculatorService/ListSessionVars"
+	CalculatorService_DeleteSession_FullMethodName      = "/calculator.CalculatorService/DeleteSession"
+	CalculatorService_WatchSession_FullMethodName       = "/calculator.CalculatorService/WatchSession"
 )
 
 // CalculatorServiceClient is the client API for CalculatorService service.
@@ -27,6 +39,18 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type CalculatorServiceClient interface {
 	Calculate(ctx context.Context, in *CalculationRequest, opts ...grpc.CallOption) (*CalculationResponse, error)
+	CalculateStream(ctx context.Context, in *CalculationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CalculationEvent], error)
+	ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error)
+	PrepareProgram(ctx context.Context, in *PrepareProgramRequest, opts ...grpc.CallOption) (*PrepareProgramResponse, error)
+	RunProgram(ctx context.Context, in *RunProgramRequest, opts ...grpc.CallOption) (*CalculationResponse, error)
+	DeleteProgram(ctx context.Context, in *DeleteProgramRequest, opts ...grpc.CallOption) (*DeleteProgramResponse, error)
+	Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error)
+	Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Instruction, SessionEvent], error)
+	CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*CreateSessionResponse, error)
+	CalculateInSession(ctx context.Context, in *CalculateInSessionRequest, opts ...grpc.CallOption) (*CalculationResponse, error)
+	ListSessionVars(ctx context.Context, in *ListSessionVarsRequest, opts ...grpc.CallOption) (*ListSessionVarsResponse, error)
+	DeleteSession(ctx context.Context, in *DeleteSessionRequest, opts ...grpc.CallOption) (*DeleteSessionResponse, error)
+	WatchSession(ctx context.Context, in *WatchSessionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Variable], error)
 }
 
 type calculatorServiceClient struct {
@@ -47,11 +71,164 @@ func (c *calculatorServiceClient) Calculate(ctx context.Context, in *Calculation
 	return out, nil
 }
 
+func (c *calculatorServiceClient) CalculateStream(ctx context.Context, in *CalculationRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CalculationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CalculatorService_ServiceDesc.Streams[0], CalculatorService_CalculateStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CalculationRequest, CalculationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_CalculateStreamClient = grpc.ServerStreamingClient[CalculationEvent]
+
+func (c *calculatorServiceClient) ListOperations(ctx context.Context, in *ListOperationsRequest, opts ...grpc.CallOption) (*ListOperationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOperationsResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_ListOperations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) PrepareProgram(ctx context.Context, in *PrepareProgramRequest, opts ...grpc.CallOption) (*PrepareProgramResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrepareProgramResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_PrepareProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) RunProgram(ctx context.Context, in *RunProgramRequest, opts ...grpc.CallOption) (*CalculationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculationResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_RunProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) DeleteProgram(ctx context.Context, in *DeleteProgramRequest, opts ...grpc.CallOption) (*DeleteProgramResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteProgramResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_DeleteProgram_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) Explain(ctx context.Context, in *ExplainRequest, opts ...grpc.CallOption) (*ExplainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_Explain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) Session(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[Instruction, SessionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CalculatorService_ServiceDesc.Streams[1], CalculatorService_Session_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[Instruction, SessionEvent]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_SessionClient = grpc.BidiStreamingClient[Instruction, SessionEvent]
+
+func (c *calculatorServiceClient) CreateSession(ctx context.Context, in *CreateSessionRequest, opts ...grpc.CallOption) (*CreateSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSessionResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_CreateSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) CalculateInSession(ctx context.Context, in *CalculateInSessionRequest, opts ...grpc.CallOption) (*CalculationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CalculationResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_CalculateInSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) ListSessionVars(ctx context.Context, in *ListSessionVarsRequest, opts ...grpc.CallOption) (*ListSessionVarsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionVarsResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_ListSessionVars_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) DeleteSession(ctx context.Context, in *DeleteSessionRequest, opts ...grpc.CallOption) (*DeleteSessionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSessionResponse)
+	err := c.cc.Invoke(ctx, CalculatorService_DeleteSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *calculatorServiceClient) WatchSession(ctx context.Context, in *WatchSessionRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Variable], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CalculatorService_ServiceDesc.Streams[2], CalculatorService_WatchSession_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchSessionRequest, Variable]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_WatchSessionClient = grpc.ServerStreamingClient[Variable]
+
 // CalculatorServiceServer is the server API for CalculatorService service.
 // All implementations must embed UnimplementedCalculatorServiceServer
 // for forward compatibility.
 type CalculatorServiceServer interface {
 	Calculate(context.Context, *CalculationRequest) (*CalculationResponse, error)
+	CalculateStream(*CalculationRequest, grpc.ServerStreamingServer[CalculationEvent]) error
+	ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error)
+	PrepareProgram(context.Context, *PrepareProgramRequest) (*PrepareProgramResponse, error)
+	RunProgram(context.Context, *RunProgramRequest) (*CalculationResponse, error)
+	DeleteProgram(context.Context, *DeleteProgramRequest) (*DeleteProgramResponse, error)
+	Explain(context.Context, *ExplainRequest) (*ExplainResponse, error)
+	Session(grpc.BidiStreamingServer[Instruction, SessionEvent]) error
+	CreateSession(context.Context, *CreateSessionRequest) (*CreateSessionResponse, error)
+	CalculateInSession(context.Context, *CalculateInSessionRequest) (*CalculationResponse, error)
+	ListSessionVars(context.Context, *ListSessionVarsRequest) (*ListSessionVarsResponse, error)
+	DeleteSession(context.Context, *DeleteSessionRequest) (*DeleteSessionResponse, error)
+	WatchSession(*WatchSessionRequest, grpc.ServerStreamingServer[Variable]) error
 	mustEmbedUnimplementedCalculatorServiceServer()
 }
 
@@ -65,6 +242,42 @@ type UnimplementedCalculatorServiceServer struct{}
 func (UnimplementedCalculatorServiceServer) Calculate(context.Context, *CalculationRequest) (*CalculationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Calculate not implemented")
 }
+func (UnimplementedCalculatorServiceServer) CalculateStream(*CalculationRequest, grpc.ServerStreamingServer[CalculationEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method CalculateStream not implemented")
+}
+func (UnimplementedCalculatorServiceServer) ListOperations(context.Context, *ListOperationsRequest) (*ListOperationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListOperations not implemented")
+}
+func (UnimplementedCalculatorServiceServer) PrepareProgram(context.Context, *PrepareProgramRequest) (*PrepareProgramResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareProgram not implemented")
+}
+func (UnimplementedCalculatorServiceServer) RunProgram(context.Context, *RunProgramRequest) (*CalculationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunProgram not implemented")
+}
+func (UnimplementedCalculatorServiceServer) DeleteProgram(context.Context, *DeleteProgramRequest) (*DeleteProgramResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteProgram not implemented")
+}
+func (UnimplementedCalculatorServiceServer) Explain(context.Context, *ExplainRequest) (*ExplainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Explain not implemented")
+}
+func (UnimplementedCalculatorServiceServer) Session(grpc.BidiStreamingServer[Instruction, SessionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Session not implemented")
+}
+func (UnimplementedCalculatorServiceServer) CreateSession(context.Context, *CreateSessionRequest) (*CreateSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSession not implemented")
+}
+func (UnimplementedCalculatorServiceServer) CalculateInSession(context.Context, *CalculateInSessionRequest) (*CalculationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CalculateInSession not implemented")
+}
+func (UnimplementedCalculatorServiceServer) ListSessionVars(context.Context, *ListSessionVarsRequest) (*ListSessionVarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessionVars not implemented")
+}
+func (UnimplementedCalculatorServiceServer) DeleteSession(context.Context, *DeleteSessionRequest) (*DeleteSessionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSession not implemented")
+}
+func (UnimplementedCalculatorServiceServer) WatchSession(*WatchSessionRequest, grpc.ServerStreamingServer[Variable]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchSession not implemented")
+}
 func (UnimplementedCalculatorServiceServer) mustEmbedUnimplementedCalculatorServiceServer() {}
 func (UnimplementedCalculatorServiceServer) testEmbeddedByValue()                           {}
 
@@ -104,6 +317,197 @@ func _CalculatorService_Calculate_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CalculatorService_CalculateStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CalculationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CalculatorServiceServer).CalculateStream(m, &grpc.GenericServerStream[CalculationRequest, CalculationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_CalculateStreamServer = grpc.ServerStreamingServer[CalculationEvent]
+
+func _CalculatorService_ListOperations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOperationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).ListOperations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_ListOperations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).ListOperations(ctx, req.(*ListOperationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_PrepareProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).PrepareProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_PrepareProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).PrepareProgram(ctx, req.(*PrepareProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_RunProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).RunProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_RunProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).RunProgram(ctx, req.(*RunProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_DeleteProgram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteProgramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).DeleteProgram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_DeleteProgram_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).DeleteProgram(ctx, req.(*DeleteProgramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_Explain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExplainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).Explain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_Explain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).Explain(ctx, req.(*ExplainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_Session_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CalculatorServiceServer).Session(&grpc.GenericServerStream[Instruction, SessionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_SessionServer = grpc.BidiStreamingServer[Instruction, SessionEvent]
+
+func _CalculatorService_CreateSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).CreateSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_CreateSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).CreateSession(ctx, req.(*CreateSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_CalculateInSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CalculateInSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).CalculateInSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_CalculateInSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).CalculateInSession(ctx, req.(*CalculateInSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_ListSessionVars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionVarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).ListSessionVars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_ListSessionVars_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).ListSessionVars(ctx, req.(*ListSessionVarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_DeleteSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CalculatorServiceServer).DeleteSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CalculatorService_DeleteSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CalculatorServiceServer).DeleteSession(ctx, req.(*DeleteSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CalculatorService_WatchSession_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchSessionRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CalculatorServiceServer).WatchSession(m, &grpc.GenericServerStream[WatchSessionRequest, Variable]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CalculatorService_WatchSessionServer = grpc.ServerStreamingServer[Variable]
+
 // CalculatorService_ServiceDesc is the grpc.ServiceDesc for CalculatorService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +519,60 @@ var CalculatorService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Calculate",
 			Handler:    _CalculatorService_Calculate_Handler,
 		},
+		{
+			MethodName: "ListOperations",
+			Handler:    _CalculatorService_ListOperations_Handler,
+		},
+		{
+			MethodName: "PrepareProgram",
+			Handler:    _CalculatorService_PrepareProgram_Handler,
+		},
+		{
+			MethodName: "RunProgram",
+			Handler:    _CalculatorService_RunProgram_Handler,
+		},
+		{
+			MethodName: "DeleteProgram",
+			Handler:    _CalculatorService_DeleteProgram_Handler,
+		},
+		{
+			MethodName: "Explain",
+			Handler:    _CalculatorService_Explain_Handler,
+		},
+		{
+			MethodName: "CreateSession",
+			Handler:    _CalculatorService_CreateSession_Handler,
+		},
+		{
+			MethodName: "CalculateInSession",
+			Handler:    _CalculatorService_CalculateInSession_Handler,
+		},
+		{
+			MethodName: "ListSessionVars",
+			Handler:    _CalculatorService_ListSessionVars_Handler,
+		},
+		{
+			MethodName: "DeleteSession",
+			Handler:    _CalculatorService_DeleteSession_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CalculateStream",
+			Handler:       _CalculatorService_CalculateStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Session",
+			Handler:       _CalculatorService_Session_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchSession",
+			Handler:       _CalculatorService_WatchSession_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "grpc/calculator.proto",
 }